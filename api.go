@@ -2,6 +2,7 @@ package auth
 
 import (
 	"context"
+	"log/slog"
 	"net/http"
 
 	"github.com/mrehanabbasi/supabase-auth-go/types"
@@ -41,6 +42,132 @@ type Client interface {
 	// It returns a copy of the client, so only requests made with the returned
 	// copy will use the new HTTP client.
 	WithClient(client *http.Client) Client
+	// WithSessionStore sets a store that sessions are persisted into whenever
+	// the Auth server returns one, i.e. from Token and the sign in methods
+	// built on it, SignInAnonymously, VerifyForUser and VerifyFactor. Logout
+	// deletes the stored session. If key is empty, types.DefaultSessionKey is used.
+	//
+	// It returns a copy of the client, so only requests made with the returned
+	// copy will use the store.
+	//
+	// If the session cannot be persisted, the method returns the response from
+	// the Auth server along with the error, so that a rotated refresh token is
+	// not lost.
+	WithSessionStore(store types.SessionStore, key string) Client
+	// WithVerifierStore sets a store that the PKCE code verifier of every flow
+	// started with FlowType pkce is saved into, under a random state. This
+	// applies to Authorize, LinkIdentity, SSO, Signup, OTP, Magiclink, Recover
+	// and UpdateUser.
+	//
+	// The state is returned in the response, and added to the redirect URL as
+	// the types.StateParam query parameter. Pass it to ExchangeCodeForSession
+	// instead of the verifier, which then loads and deletes the verifier so
+	// that the code can only be exchanged once.
+	//
+	// It returns a copy of the client, so only requests made with the returned
+	// copy will use the store.
+	WithVerifierStore(store types.VerifierStore) Client
+	// WithRetryPolicy sets how requests that fail with a transient error,
+	// such as a network error, 429 or 503, are retried. Retries back off
+	// exponentially with jitter, and honour the Retry-After header. By
+	// default, requests are not retried. See types.RetryPolicy for which
+	// requests are retried, and types.DefaultRetryPolicy for a sensible
+	// starting point.
+	//
+	// It returns a copy of the client, so only requests made with the returned
+	// copy will use the policy.
+	WithRetryPolicy(policy types.RetryPolicy) Client
+	// WithInterceptors adds interceptors that every request made by the
+	// client passes through, in order, e.g. to add headers, record timings or
+	// return canned responses in tests. See types.Interceptor.
+	//
+	// It returns a copy of the client, so only requests made with the returned
+	// copy will use the interceptors. Interceptors are added after those the
+	// client already has.
+	WithInterceptors(interceptors ...types.Interceptor) Client
+	// WithLogger sets a logger that every request made by the client is
+	// logged to, with its endpoint, method, status, duration, error code and
+	// X-Request-Id. Failed requests are logged at warn or error level, others
+	// at info level. If debug level is enabled, the request headers and the
+	// request and response bodies are logged too.
+	//
+	// Passwords, tokens, nonces, OTP codes, captcha tokens, TOTP secrets and
+	// the apiKey and Authorization headers are always redacted.
+	//
+	// It returns a copy of the client, so only requests made with the returned
+	// copy will be logged.
+	WithLogger(logger *slog.Logger) Client
+	// WithObserver sets an observer that every request made by the client is
+	// reported to, as a span, a count and a latency labelled by endpoint and
+	// outcome. See types.Observer.
+	//
+	// It returns a copy of the client, so only requests made with the returned
+	// copy will be reported.
+	WithObserver(observer types.Observer) Client
+	// WithPasswordPolicy sets the password rules the Auth server is configured
+	// with. New passwords passed to Signup, UpdateUser and AdminCreateUser are
+	// checked against them, and rejected with an *endpoints.WeakPasswordError
+	// without sending the request if they break any.
+	//
+	// It returns a copy of the client, so only requests made with the returned
+	// copy will be checked.
+	WithPasswordPolicy(policy types.PasswordPolicy) Client
+	// WithRateLimitPolicy limits the requests made by the client, per endpoint
+	// and per recipient of the messages sent by Recover, Magiclink, OTP, Invite
+	// and Resend, so that batch jobs stay within the Auth server's limits.
+	// Calls over a limit wait, if policy.Wait is set, or fail with an
+	// *endpoints.RateLimitError saying when to try again. See
+	// types.DefaultRateLimitPolicy.
+	//
+	// It returns a copy of the client. The limits are shared with copies made
+	// from it.
+	WithRateLimitPolicy(policy types.RateLimitPolicy) Client
+	// WithCircuitBreaker stops the client sending requests while the Auth
+	// server is failing, so that callers get endpoints.ErrCircuitOpen straight
+	// away instead of waiting for each request to time out. The circuit opens
+	// after a number of consecutive failures or a failure rate set by policy,
+	// and after policy.OpenTimeout lets a probe through, optionally a
+	// HealthCheck, to decide whether to close again. See
+	// types.DefaultCircuitBreakerPolicy.
+	//
+	// It returns a copy of the client. The circuit is shared with copies made
+	// from it.
+	WithCircuitBreaker(policy types.CircuitBreakerPolicy) Client
+	// CircuitState returns the state of the client's circuit breaker, e.g. to
+	// report it from a readiness check. It is always closed if the client has
+	// none.
+	CircuitState() types.CircuitState
+
+	// ValidatePassword checks password against the client's password policy,
+	// e.g. to give feedback while the user types it. It returns an
+	// *endpoints.WeakPasswordError listing every rule the password breaks, and
+	// always succeeds if the client has no policy.
+	ValidatePassword(ctx context.Context, password string) error
+
+	// Events:
+
+	// OnAuthStateChange registers fn to be called whenever a request made with
+	// this client, or any copy of it, changes the session state:
+	//
+	//   - SIGNED_IN from Token and the sign in methods, SignInAnonymously, Verify
+	//     and VerifyForUser.
+	//   - TOKEN_REFRESHED from Token with the refresh_token grant type.
+	//   - PASSWORD_RECOVERY from Verify and VerifyForUser with a recovery token.
+	//   - MFA_CHALLENGE_VERIFIED from VerifyFactor.
+	//   - USER_UPDATED from UpdateUser.
+	//   - SIGNED_OUT from Logout, and from LogoutWithScope unless the scope is
+	//     others.
+	//
+	// It returns a function that unsubscribes fn.
+	//
+	// Each subscriber is called from its own goroutine, in the order the events
+	// occurred, so a slow subscriber does not block requests or other
+	// subscribers. If more than 100 events are waiting for it, the oldest are
+	// dropped, and the Dropped field of the next event delivered says how many.
+	//
+	// Verify fetches the user with GetUser before emitting its event, and
+	// emits none if that fails.
+	OnAuthStateChange(fn func(types.AuthStateChange)) (unsubscribe func())
 
 	// Endpoints:
 
@@ -160,7 +287,16 @@ type Client interface {
 	// Callback endpoint for external oauth providers to redirect to.
 	//
 	// There is no meaningful implementation of this as a client method, so it is
-	// not included here.
+	// not included here. To handle the redirect back to your own service after
+	// signing in with an external provider, see handlers.OAuthCallback.
+
+	// GET /.well-known/jwks.json
+	//
+	// Get the JSON Web Key Set containing the public keys used to sign access
+	// tokens with asymmetric algorithms such as RS256 and ES256.
+	//
+	// To verify access tokens with these keys, see verifier.NewJWKS.
+	GetJWKS(ctx context.Context) (*types.JWKSResponse, error)
 
 	// GET /health
 	//
@@ -181,6 +317,18 @@ type Client interface {
 	// This will revoke all refresh tokens for the user. Remember that the JWT
 	// tokens will still be valid for stateless auth until they expires.
 	Logout(ctx context.Context) error
+	// POST /logout?scope={scope}
+	//
+	// Logout a user with the given scope (Requires authentication).
+	//
+	// Scope is one of global, local or others, and defaults to global, which is
+	// the same as Logout. Local revokes only the current session, e.g. to sign
+	// out of this device, and others revokes every session except the current
+	// one.
+	//
+	// Unless the scope is others, the stored session is deleted if a session
+	// store is configured.
+	LogoutWithScope(ctx context.Context, req types.LogoutRequest) error
 
 	// POST /magiclink
 	//
@@ -191,14 +339,43 @@ type Client interface {
 	// address which they can use to redeem an access_token.
 	//
 	// By default Magic Links can only be sent once every 60 seconds.
+	//
+	// If FlowType is pkce, the client must have a verifier store, which the
+	// verifier is saved into. Otherwise endpoints.ErrVerifierStoreRequired is
+	// returned. Use MagiclinkWithPKCE to get the verifier back instead.
 	Magiclink(ctx context.Context, req types.MagiclinkRequest) error
+	// MagiclinkWithPKCE is like Magiclink, but returns the verifier of the PKCE flow
+	// started if FlowType is pkce, to exchange the auth code with. If the
+	// client has a verifier store, the verifier is also saved into it.
+	// It is reported under the same endpoint name as Magiclink.
+	MagiclinkWithPKCE(ctx context.Context, req types.MagiclinkRequest) (*types.MagiclinkResponse, error)
 	// POST /otp
 	// One-Time-Password. Will deliver a magiclink or SMS OTP to the user depending
 	// on whether the request contains an email or phone key.
 	//
 	// If CreateUser is true, the user will be automatically signed up if the user
 	// doesn't exist.
+	//
+	// If FlowType is pkce, the client must have a verifier store, which the
+	// verifier is saved into. Otherwise endpoints.ErrVerifierStoreRequired is
+	// returned. Use OTPWithPKCE to get the verifier back instead.
 	OTP(ctx context.Context, req types.OTPRequest) error
+	// OTPWithPKCE is like OTP, but returns the verifier of the PKCE flow
+	// started if FlowType is pkce, to exchange the auth code with. If the
+	// client has a verifier store, the verifier is also saved into it.
+	// It is reported under the same endpoint name as OTP.
+	OTPWithPKCE(ctx context.Context, req types.OTPRequest) (*types.OTPResponse, error)
+	// POST /resend
+	//
+	// Resend an existing signup confirmation, email change, SMS OTP or phone
+	// change OTP to the user. Provide Email for the signup and email_change
+	// types, and Phone for the sms and phone_change types.
+	//
+	// Sending is rate limited by the Auth server. If the limit is reached for
+	// an email, endpoints.ErrEmailSendLimitExceeded is returned. Use
+	// endpoints.IsRateLimited to check for any rate limit, including those for
+	// SMS.
+	Resend(ctx context.Context, req types.ResendRequest) (*types.ResendResponse, error)
 
 	// GET /reauthenticate
 	//
@@ -213,7 +390,16 @@ type Client interface {
 	// on email address.
 	//
 	// By default recovery links can only be sent once every 60 seconds.
+	//
+	// If FlowType is pkce, the client must have a verifier store, which the
+	// verifier is saved into. Otherwise endpoints.ErrVerifierStoreRequired is
+	// returned. Use RecoverWithPKCE to get the verifier back instead.
 	Recover(ctx context.Context, req types.RecoverRequest) error
+	// RecoverWithPKCE is like Recover, but returns the verifier of the PKCE flow
+	// started if FlowType is pkce, to exchange the auth code with. If the
+	// client has a verifier store, the verifier is also saved into it.
+	// It is reported under the same endpoint name as Recover.
+	RecoverWithPKCE(ctx context.Context, req types.RecoverRequest) (*types.RecoverResponse, error)
 
 	// GET /settings
 	//
@@ -223,8 +409,31 @@ type Client interface {
 	// POST /signup
 	//
 	// Register a new user with an email and password.
+	//
+	// If FlowType is pkce, the confirmation link carries an auth code instead of
+	// tokens, and the response contains the verifier to exchange it with.
 	Signup(ctx context.Context, req types.SignupRequest) (*types.SignupResponse, error)
 
+	// POST /signup
+	//
+	// Sign in as a new anonymous user. Anonymous sign-ins must be enabled on the
+	// Auth server.
+	//
+	// The returned session belongs to a user with IsAnonymous set. Use
+	// ConvertAnonymousUser to turn it into a permanent user, keeping its ID and
+	// any data associated with it.
+	SignInAnonymously(ctx context.Context, req types.SignInAnonymouslyRequest) (*types.SignInAnonymouslyResponse, error)
+	// Convert an anonymous user into a permanent user
+	//
+	// This is a convenience method that checks the logged in user is anonymous,
+	// then calls UpdateUser to attach an email or phone, and optionally a
+	// password (requires authentication as the anonymous user).
+	//
+	// The Auth server sends a confirmation to the new email or phone. The user
+	// keeps its ID, so data associated with the anonymous user is kept, and
+	// stops being anonymous once the email or phone is verified.
+	ConvertAnonymousUser(ctx context.Context, req types.ConvertAnonymousUserRequest) (*types.UpdateUserResponse, error)
+
 	// Sign in with email and password
 	//
 	// This is a convenience method that calls Token with the password grant type
@@ -244,6 +453,13 @@ type Client interface {
 	//
 	// This is a convenience method that calls Token with the refresh_token grant type
 	RefreshToken(ctx context.Context, refreshToken string) (*types.TokenResponse, error)
+	// Exchange an auth code for a session
+	//
+	// This is a convenience method that calls Token with the pkce grant type. Use
+	// it to complete flows started with FlowType set to pkce, passing the auth
+	// code the user was redirected back with and the verifier returned when the
+	// flow was started, or its state if the client has a VerifierStore.
+	ExchangeCodeForSession(ctx context.Context, req types.ExchangeCodeForSessionRequest) (*types.TokenResponse, error)
 	// POST /token
 	//
 	// This is an OAuth2 endpoint that currently implements the password, id_token,
@@ -259,7 +475,38 @@ type Client interface {
 	// Update a user (Requires authentication). Apart from changing email/password,
 	// this method can be used to set custom user data. Changing the email will
 	// result in a magiclink being sent out.
+	//
+	// If FlowType is pkce, the email change link carries an auth code instead of
+	// tokens, and the response contains the verifier to exchange it with.
 	UpdateUser(ctx context.Context, req types.UpdateUserRequest) (*types.UpdateUserResponse, error)
+	// Get the identities linked to the logged in user
+	//
+	// This is a convenience method that calls GetUser and returns the user's
+	// identities (requires authentication).
+	GetUserIdentities(ctx context.Context) (*types.GetUserIdentitiesResponse, error)
+	// GET /user/identities/authorize
+	//
+	// Link an identity from an external oauth provider to the logged in user
+	// (requires authentication). Manual linking must be enabled on the Auth
+	// server.
+	//
+	// Scopes are optional additional scopes depending on the provider (email and
+	// name are requested by default).
+	//
+	// Like Authorize, this method returns the URL the user should be redirected
+	// to. Once the user signs in with the provider, the identity is added to
+	// their account.
+	LinkIdentity(ctx context.Context, req types.LinkIdentityRequest) (*types.LinkIdentityResponse, error)
+	// DELETE /user/identities/{identity_id}
+	//
+	// Unlink an identity from the logged in user (requires authentication).
+	// Manual linking must be enabled on the Auth server.
+	//
+	// The user's identities are fetched first, and the request is refused with
+	// an *endpoints.APIError matching endpoints.ErrIdentityNotFound if the
+	// identity is not the user's, or endpoints.ErrSingleIdentityNotDeletable if
+	// it would leave the user without a way to sign in.
+	UnlinkIdentity(ctx context.Context, req types.UnlinkIdentityRequest) error
 
 	// GET /verify
 	//
@@ -281,9 +528,9 @@ type Client interface {
 	// or magiclink or invite and the token is a token returned from either /signup
 	// or /recover or /magiclink.
 	//
-	// This differs from GET /verify as it requires an email or phone to be given,
-	// which is used to verify the token associated to the user. It also returns a
-	// JSON response rather than a redirect.
+	// This differs from GET /verify as it requires either a token hash, or an
+	// email or phone that is used to verify the token associated to the user. It
+	// also returns a JSON response rather than a redirect.
 	VerifyForUser(ctx context.Context, req types.VerifyForUserRequest) (*types.VerifyForUserResponse, error)
 
 	// GET /sso/saml/metadata
@@ -327,5 +574,8 @@ type Client interface {
 	// Auth allows you to skip following the redirect by setting SkipHTTPRedirect
 	// on the request struct. In this case, the URL to redirect to will be returned
 	// in the response.
+	//
+	// If FlowType is pkce, the user is redirected back with an auth code instead
+	// of tokens, and the response contains the verifier to exchange it with.
 	SSO(ctx context.Context, req types.SSORequest) (*types.SSOResponse, error)
 }
@@ -0,0 +1,94 @@
+// Package breached checks passwords against lists of passwords exposed in
+// data breaches, for use as a types.PasswordPolicy's Breached checker.
+package breached
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/mrehanabbasi/supabase-auth-go/types"
+)
+
+var _ types.BreachedPasswordChecker = &HashList{}
+
+// HashList is a types.BreachedPasswordChecker backed by an in-memory list of
+// SHA-1 hashes of breached passwords, so no password ever leaves the process.
+//
+// It reads the format of the Pwned Passwords downloads, one hex encoded hash
+// per line, optionally followed by ":" and a count. The full list is too big
+// to keep in memory, so use a subset, e.g. the hashes seen most often.
+type HashList struct {
+	hashes map[[sha1.Size]byte]struct{}
+}
+
+// NewHashList returns a list of the given hex encoded SHA-1 hashes.
+func NewHashList(hashes ...string) (*HashList, error) {
+	l := &HashList{
+		hashes: make(map[[sha1.Size]byte]struct{}, len(hashes)),
+	}
+	for _, hash := range hashes {
+		if err := l.add(hash); err != nil {
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+// ReadHashList reads a list of hashes from r. Blank lines are skipped.
+func ReadHashList(r io.Reader) (*HashList, error) {
+	l, _ := NewHashList()
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		hash, _, _ := strings.Cut(text, ":")
+		if err := l.add(hash); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// LoadHashList reads a list of hashes from the file at path.
+func LoadHashList(path string) (*HashList, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadHashList(f)
+}
+
+// Len returns the number of hashes in the list.
+func (l *HashList) Len() int {
+	return len(l.hashes)
+}
+
+// IsBreached reports whether the SHA-1 hash of password is in the list.
+func (l *HashList) IsBreached(_ context.Context, password string) (bool, error) {
+	_, ok := l.hashes[sha1.Sum([]byte(password))]
+	return ok, nil
+}
+
+func (l *HashList) add(hash string) error {
+	var sum [sha1.Size]byte
+	if len(hash) != hex.EncodedLen(sha1.Size) {
+		return fmt.Errorf("invalid SHA-1 hash %q", hash)
+	}
+	if _, err := hex.Decode(sum[:], []byte(hash)); err != nil {
+		return fmt.Errorf("invalid SHA-1 hash %q: %w", hash, err)
+	}
+	l.hashes[sum] = struct{}{}
+	return nil
+}
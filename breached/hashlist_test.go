@@ -0,0 +1,46 @@
+package breached_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrehanabbasi/supabase-auth-go/breached"
+	"github.com/mrehanabbasi/supabase-auth-go/endpoints"
+	"github.com/mrehanabbasi/supabase-auth-go/types"
+)
+
+func TestHashList(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	ctx := context.Background()
+
+	// SHA-1 hashes of "password" and "123456", in the Pwned Passwords format.
+	list, err := breached.ReadHashList(strings.NewReader(
+		"5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8:52256179\r\n\n7c4a8d09ca3762af61e59520943dc26494f8941b\n",
+	))
+	require.NoError(err)
+	assert.Equal(2, list.Len())
+
+	ok, err := list.IsBreached(ctx, "password")
+	require.NoError(err)
+	assert.True(ok)
+	ok, err = list.IsBreached(ctx, "correct horse battery staple")
+	require.NoError(err)
+	assert.False(ok)
+
+	err = endpoints.ValidatePassword(ctx, types.PasswordPolicy{Breached: list}, "123456")
+	var weakErr *endpoints.WeakPasswordError
+	require.ErrorAs(err, &weakErr)
+	assert.Equal([]endpoints.WeakPasswordReason{endpoints.WeakPasswordReasonPwned}, weakErr.Reasons)
+
+	_, err = breached.ReadHashList(strings.NewReader("5baa61e4c9b93f3f0682250b6cf8331b7ee68fd8\nnot a hash\n"))
+	assert.ErrorContains(err, "line 2")
+	_, err = breached.NewHashList("5baa61e4c9b93f3f0682250b6cf8331b7ee68fdz")
+	assert.Error(err)
+	_, err = breached.LoadHashList("testdata/missing.txt")
+	assert.Error(err)
+}
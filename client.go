@@ -2,9 +2,11 @@ package auth
 
 import (
 	"errors"
+	"log/slog"
 	"net/http"
 
 	"github.com/mrehanabbasi/supabase-auth-go/endpoints"
+	"github.com/mrehanabbasi/supabase-auth-go/types"
 )
 
 var ErrInvalidProjectReference = errors.New("cannot create auth client: invalid project reference")
@@ -87,3 +89,57 @@ func (c client) WithClient(httpClient *http.Client) Client {
 		Client: c.Client.WithClient(httpClient),
 	}
 }
+
+func (c client) WithSessionStore(store types.SessionStore, key string) Client {
+	return &client{
+		Client: c.Client.WithSessionStore(store, key),
+	}
+}
+
+func (c client) WithVerifierStore(store types.VerifierStore) Client {
+	return &client{
+		Client: c.Client.WithVerifierStore(store),
+	}
+}
+
+func (c client) WithRetryPolicy(policy types.RetryPolicy) Client {
+	return &client{
+		Client: c.Client.WithRetryPolicy(policy),
+	}
+}
+
+func (c client) WithInterceptors(interceptors ...types.Interceptor) Client {
+	return &client{
+		Client: c.Client.WithInterceptors(interceptors...),
+	}
+}
+
+func (c client) WithLogger(logger *slog.Logger) Client {
+	return &client{
+		Client: c.Client.WithLogger(logger),
+	}
+}
+
+func (c client) WithObserver(observer types.Observer) Client {
+	return &client{
+		Client: c.Client.WithObserver(observer),
+	}
+}
+
+func (c client) WithPasswordPolicy(policy types.PasswordPolicy) Client {
+	return &client{
+		Client: c.Client.WithPasswordPolicy(policy),
+	}
+}
+
+func (c client) WithRateLimitPolicy(policy types.RateLimitPolicy) Client {
+	return &client{
+		Client: c.Client.WithRateLimitPolicy(policy),
+	}
+}
+
+func (c client) WithCircuitBreaker(policy types.CircuitBreakerPolicy) Client {
+	return &client{
+		Client: c.Client.WithCircuitBreaker(policy),
+	}
+}
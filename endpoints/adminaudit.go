@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -37,9 +36,9 @@ func (c *Client) AdminAudit(ctx context.Context, req types.AdminAuditRequest) (*
 		}
 	}
 
-	r, err := c.newRequest(ctx, adminAuditPath, http.MethodGet, nil)
+	r, err := c.newRequest(ctx, "AdminAudit", adminAuditPath, http.MethodGet, nil)
 	if err != nil {
-		return nil, err
+		return nil, newRequestCreationError(err)
 	}
 
 	q := r.URL.Query()
@@ -52,23 +51,19 @@ func (c *Client) AdminAudit(ctx context.Context, req types.AdminAuditRequest) (*
 	}
 	r.URL.RawQuery = q.Encode()
 
-	resp, err := c.client.Do(r)
+	resp, err := c.do(r)
 	if err != nil {
-		return nil, err
+		return nil, newRequestDispatchError(err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		fullBody, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return nil, fmt.Errorf("response status code %d", resp.StatusCode)
-		}
-		return nil, fmt.Errorf("response status code %d: %s", resp.StatusCode, fullBody)
+		return nil, handleErrorResponse(resp)
 	}
 
 	var logs []types.AuditLogEntry
 	if err := json.NewDecoder(resp.Body).Decode(&logs); err != nil {
-		return nil, err
+		return nil, newResponseDecodingError(err)
 	}
 
 	// Result count should be given in X-Total-Count header.
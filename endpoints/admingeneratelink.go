@@ -4,8 +4,6 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
-	"fmt"
-	"io"
 	"net/http"
 
 	"github.com/mrehanabbasi/supabase-auth-go/types"
@@ -65,32 +63,28 @@ func (c *Client) AdminGenerateLink(ctx context.Context, req types.AdminGenerateL
 
 	body, err := json.Marshal(req)
 	if err != nil {
-		return nil, err
+		return nil, newRequestEncodingError(err)
 	}
 
-	r, err := c.newRequest(ctx, adminGenerateLinkPath, http.MethodPost, bytes.NewBuffer(body))
+	r, err := c.newRequest(ctx, "AdminGenerateLink", adminGenerateLinkPath, http.MethodPost, bytes.NewBuffer(body))
 	if err != nil {
-		return nil, err
+		return nil, newRequestCreationError(err)
 	}
 
-	resp, err := c.client.Do(r)
+	resp, err := c.do(r)
 	if err != nil {
-		return nil, err
+		return nil, newRequestDispatchError(err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		fullBody, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return nil, fmt.Errorf("response status code %d", resp.StatusCode)
-		}
-		return nil, fmt.Errorf("response status code %d: %s", resp.StatusCode, fullBody)
+		return nil, handleErrorResponse(resp)
 	}
 
 	var res types.AdminGenerateLinkResponse
 	err = json.NewDecoder(resp.Body).Decode(&res)
 	if err != nil {
-		return nil, err
+		return nil, newResponseDecodingError(err)
 	}
 
 	return &res, nil
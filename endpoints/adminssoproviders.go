@@ -5,7 +5,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 
 	"github.com/mrehanabbasi/supabase-auth-go/types"
@@ -17,29 +16,25 @@ const adminSSOPath = "/admin/sso/providers"
 //
 // Get a list of all SAML SSO Identity Providers in the system.
 func (c *Client) AdminListSSOProviders(ctx context.Context) (*types.AdminListSSOProvidersResponse, error) {
-	r, err := c.newRequest(ctx, adminSSOPath, http.MethodGet, nil)
+	r, err := c.newRequest(ctx, "AdminListSSOProviders", adminSSOPath, http.MethodGet, nil)
 	if err != nil {
-		return nil, err
+		return nil, newRequestCreationError(err)
 	}
 
-	resp, err := c.client.Do(r)
+	resp, err := c.do(r)
 	if err != nil {
-		return nil, err
+		return nil, newRequestDispatchError(err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		fullBody, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return nil, fmt.Errorf("response status code %d", resp.StatusCode)
-		}
-		return nil, fmt.Errorf("response status code %d: %s", resp.StatusCode, fullBody)
+		return nil, handleErrorResponse(resp)
 	}
 
 	var res types.AdminListSSOProvidersResponse
 	err = json.NewDecoder(resp.Body).Decode(&res)
 	if err != nil {
-		return nil, err
+		return nil, newResponseDecodingError(err)
 	}
 
 	return &res, nil
@@ -51,32 +46,28 @@ func (c *Client) AdminListSSOProviders(ctx context.Context) (*types.AdminListSSO
 func (c *Client) AdminCreateSSOProvider(ctx context.Context, req types.AdminCreateSSOProviderRequest) (*types.AdminCreateSSOProviderResponse, error) {
 	body, err := json.Marshal(req)
 	if err != nil {
-		return nil, err
+		return nil, newRequestEncodingError(err)
 	}
 
-	r, err := c.newRequest(ctx, adminSSOPath, http.MethodPost, bytes.NewBuffer(body))
+	r, err := c.newRequest(ctx, "AdminCreateSSOProvider", adminSSOPath, http.MethodPost, bytes.NewBuffer(body))
 	if err != nil {
-		return nil, err
+		return nil, newRequestCreationError(err)
 	}
 
-	resp, err := c.client.Do(r)
+	resp, err := c.do(r)
 	if err != nil {
-		return nil, err
+		return nil, newRequestDispatchError(err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		fullBody, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return nil, fmt.Errorf("response status code %d", resp.StatusCode)
-		}
-		return nil, fmt.Errorf("response status code %d: %s", resp.StatusCode, fullBody)
+		return nil, handleErrorResponse(resp)
 	}
 
 	var res types.AdminCreateSSOProviderResponse
 	err = json.NewDecoder(resp.Body).Decode(&res)
 	if err != nil {
-		return nil, err
+		return nil, newResponseDecodingError(err)
 	}
 
 	return &res, nil
@@ -86,29 +77,25 @@ func (c *Client) AdminCreateSSOProvider(ctx context.Context, req types.AdminCrea
 //
 // Get a SAML SSO Identity Provider by ID.
 func (c *Client) AdminGetSSOProvider(ctx context.Context, req types.AdminGetSSOProviderRequest) (*types.AdminGetSSOProviderResponse, error) {
-	r, err := c.newRequest(ctx, fmt.Sprintf("%s/%s", adminSSOPath, req.ProviderID), http.MethodGet, nil)
+	r, err := c.newRequest(ctx, "AdminGetSSOProvider", fmt.Sprintf("%s/%s", adminSSOPath, req.ProviderID), http.MethodGet, nil)
 	if err != nil {
-		return nil, err
+		return nil, newRequestCreationError(err)
 	}
 
-	resp, err := c.client.Do(r)
+	resp, err := c.do(r)
 	if err != nil {
-		return nil, err
+		return nil, newRequestDispatchError(err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		fullBody, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return nil, fmt.Errorf("response status code %d", resp.StatusCode)
-		}
-		return nil, fmt.Errorf("response status code %d: %s", resp.StatusCode, fullBody)
+		return nil, handleErrorResponse(resp)
 	}
 
 	var res types.AdminGetSSOProviderResponse
 	err = json.NewDecoder(resp.Body).Decode(&res)
 	if err != nil {
-		return nil, err
+		return nil, newResponseDecodingError(err)
 	}
 
 	return &res, nil
@@ -123,32 +110,28 @@ func (c *Client) AdminUpdateSSOProvider(
 ) (*types.AdminUpdateSSOProviderResponse, error) {
 	body, err := json.Marshal(req)
 	if err != nil {
-		return nil, err
+		return nil, newRequestEncodingError(err)
 	}
 
-	r, err := c.newRequest(ctx, fmt.Sprintf("%s/%s", adminSSOPath, req.ProviderID), http.MethodPut, bytes.NewBuffer(body))
+	r, err := c.newRequest(ctx, "AdminUpdateSSOProvider", fmt.Sprintf("%s/%s", adminSSOPath, req.ProviderID), http.MethodPut, bytes.NewBuffer(body))
 	if err != nil {
-		return nil, err
+		return nil, newRequestCreationError(err)
 	}
 
-	resp, err := c.client.Do(r)
+	resp, err := c.do(r)
 	if err != nil {
-		return nil, err
+		return nil, newRequestDispatchError(err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		fullBody, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return nil, fmt.Errorf("response status code %d", resp.StatusCode)
-		}
-		return nil, fmt.Errorf("response status code %d: %s", resp.StatusCode, fullBody)
+		return nil, handleErrorResponse(resp)
 	}
 
 	var res types.AdminUpdateSSOProviderResponse
 	err = json.NewDecoder(resp.Body).Decode(&res)
 	if err != nil {
-		return nil, err
+		return nil, newResponseDecodingError(err)
 	}
 
 	return &res, nil
@@ -162,29 +145,25 @@ func (c *Client) AdminDeleteSSOProvider(
 	req types.AdminDeleteSSOProviderRequest,
 ) (*types.AdminDeleteSSOProviderResponse, error) {
 	path := fmt.Sprintf("%s/%s", adminSSOPath, req.ProviderID)
-	r, err := c.newRequest(ctx, path, http.MethodDelete, nil)
+	r, err := c.newRequest(ctx, "AdminDeleteSSOProvider", path, http.MethodDelete, nil)
 	if err != nil {
-		return nil, err
+		return nil, newRequestCreationError(err)
 	}
 
-	resp, err := c.client.Do(r)
+	resp, err := c.do(r)
 	if err != nil {
-		return nil, err
+		return nil, newRequestDispatchError(err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		fullBody, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return nil, fmt.Errorf("response status code %d", resp.StatusCode)
-		}
-		return nil, fmt.Errorf("response status code %d: %s", resp.StatusCode, fullBody)
+		return nil, handleErrorResponse(resp)
 	}
 
 	var res types.AdminDeleteSSOProviderResponse
 	err = json.NewDecoder(resp.Body).Decode(&res)
 	if err != nil {
-		return nil, err
+		return nil, newResponseDecodingError(err)
 	}
 
 	return &res, nil
@@ -5,7 +5,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 
 	"github.com/mrehanabbasi/supabase-auth-go/types"
@@ -17,34 +16,36 @@ const adminUsersPath = "/admin/users"
 //
 // Creates the user based on the user_id specified.
 func (c *Client) AdminCreateUser(ctx context.Context, req types.AdminCreateUserRequest) (*types.AdminCreateUserResponse, error) {
+	if req.Password != nil {
+		if err := c.ValidatePassword(ctx, *req.Password); err != nil {
+			return nil, err
+		}
+	}
+
 	body, err := json.Marshal(req)
 	if err != nil {
-		return nil, err
+		return nil, newRequestEncodingError(err)
 	}
 
-	r, err := c.newRequest(ctx, adminUsersPath, http.MethodPost, bytes.NewBuffer(body))
+	r, err := c.newRequest(ctx, "AdminCreateUser", adminUsersPath, http.MethodPost, bytes.NewBuffer(body))
 	if err != nil {
-		return nil, err
+		return nil, newRequestCreationError(err)
 	}
 
-	resp, err := c.client.Do(r)
+	resp, err := c.do(r)
 	if err != nil {
-		return nil, err
+		return nil, newRequestDispatchError(err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		fullBody, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return nil, fmt.Errorf("response status code %d", resp.StatusCode)
-		}
-		return nil, fmt.Errorf("response status code %d: %s", resp.StatusCode, fullBody)
+		return nil, handleErrorResponse(resp)
 	}
 
 	var res types.AdminCreateUserResponse
 	err = json.NewDecoder(resp.Body).Decode(&res)
 	if err != nil {
-		return nil, err
+		return nil, newResponseDecodingError(err)
 	}
 
 	return &res, nil
@@ -54,9 +55,9 @@ func (c *Client) AdminCreateUser(ctx context.Context, req types.AdminCreateUserR
 //
 // Get a list of users.
 func (c *Client) AdminListUsers(ctx context.Context, req types.AdminListUsersRequest) (*types.AdminListUsersResponse, error) {
-	r, err := c.newRequest(ctx, adminUsersPath, http.MethodGet, nil)
+	r, err := c.newRequest(ctx, "AdminListUsers", adminUsersPath, http.MethodGet, nil)
 	if err != nil {
-		return nil, err
+		return nil, newRequestCreationError(err)
 	}
 
 	q := r.URL.Query()
@@ -68,24 +69,20 @@ func (c *Client) AdminListUsers(ctx context.Context, req types.AdminListUsersReq
 	}
 	r.URL.RawQuery = q.Encode()
 
-	resp, err := c.client.Do(r)
+	resp, err := c.do(r)
 	if err != nil {
-		return nil, err
+		return nil, newRequestDispatchError(err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		fullBody, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return nil, fmt.Errorf("response status code %d", resp.StatusCode)
-		}
-		return nil, fmt.Errorf("response status code %d: %s", resp.StatusCode, fullBody)
+		return nil, handleErrorResponse(resp)
 	}
 
 	var res types.AdminListUsersResponse
 	err = json.NewDecoder(resp.Body).Decode(&res)
 	if err != nil {
-		return nil, err
+		return nil, newResponseDecodingError(err)
 	}
 
 	return &res, nil
@@ -96,29 +93,25 @@ func (c *Client) AdminListUsers(ctx context.Context, req types.AdminListUsersReq
 // Get a user by their user_id.
 func (c *Client) AdminGetUser(ctx context.Context, req types.AdminGetUserRequest) (*types.AdminGetUserResponse, error) {
 	path := fmt.Sprintf("%s/%s", adminUsersPath, req.UserID)
-	r, err := c.newRequest(ctx, path, http.MethodGet, nil)
+	r, err := c.newRequest(ctx, "AdminGetUser", path, http.MethodGet, nil)
 	if err != nil {
-		return nil, err
+		return nil, newRequestCreationError(err)
 	}
 
-	resp, err := c.client.Do(r)
+	resp, err := c.do(r)
 	if err != nil {
-		return nil, err
+		return nil, newRequestDispatchError(err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		fullBody, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return nil, fmt.Errorf("response status code %d", resp.StatusCode)
-		}
-		return nil, fmt.Errorf("response status code %d: %s", resp.StatusCode, fullBody)
+		return nil, handleErrorResponse(resp)
 	}
 
 	var res types.AdminGetUserResponse
 	err = json.NewDecoder(resp.Body).Decode(&res)
 	if err != nil {
-		return nil, err
+		return nil, newResponseDecodingError(err)
 	}
 
 	return &res, nil
@@ -131,32 +124,28 @@ func (c *Client) AdminUpdateUser(ctx context.Context, req types.AdminUpdateUserR
 	path := fmt.Sprintf("%s/%s", adminUsersPath, req.UserID)
 	body, err := json.Marshal(req)
 	if err != nil {
-		return nil, err
+		return nil, newRequestEncodingError(err)
 	}
 
-	r, err := c.newRequest(ctx, path, http.MethodPut, bytes.NewBuffer(body))
+	r, err := c.newRequest(ctx, "AdminUpdateUser", path, http.MethodPut, bytes.NewBuffer(body))
 	if err != nil {
-		return nil, err
+		return nil, newRequestCreationError(err)
 	}
 
-	resp, err := c.client.Do(r)
+	resp, err := c.do(r)
 	if err != nil {
-		return nil, err
+		return nil, newRequestDispatchError(err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		fullBody, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return nil, fmt.Errorf("response status code %d", resp.StatusCode)
-		}
-		return nil, fmt.Errorf("response status code %d: %s", resp.StatusCode, fullBody)
+		return nil, handleErrorResponse(resp)
 	}
 
 	var res types.AdminUpdateUserResponse
 	err = json.NewDecoder(resp.Body).Decode(&res)
 	if err != nil {
-		return nil, err
+		return nil, newResponseDecodingError(err)
 	}
 
 	return &res, nil
@@ -167,12 +156,12 @@ func (c *Client) AdminUpdateUser(ctx context.Context, req types.AdminUpdateUserR
 // Delete a user by their user_id.
 func (c *Client) AdminDeleteUser(ctx context.Context, req types.AdminDeleteUserRequest) error {
 	path := fmt.Sprintf("%s/%s", adminUsersPath, req.UserID)
-	r, err := c.newRequest(ctx, path, http.MethodDelete, nil)
+	r, err := c.newRequest(ctx, "AdminDeleteUser", path, http.MethodDelete, nil)
 	if err != nil {
 		return newRequestCreationError(err)
 	}
 
-	resp, err := c.client.Do(r)
+	resp, err := c.do(r)
 	if err != nil {
 		return newRequestDispatchError(err)
 	}
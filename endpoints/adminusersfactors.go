@@ -5,7 +5,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 
 	"github.com/mrehanabbasi/supabase-auth-go/types"
@@ -20,29 +19,25 @@ func (c *Client) AdminListUserFactors(
 ) (*types.AdminListUserFactorsResponse, error) {
 	path := fmt.Sprintf("%s/%s/factors", adminUsersPath, req.UserID)
 
-	r, err := c.newRequest(ctx, path, http.MethodGet, nil)
+	r, err := c.newRequest(ctx, "AdminListUserFactors", path, http.MethodGet, nil)
 	if err != nil {
-		return nil, err
+		return nil, newRequestCreationError(err)
 	}
 
-	resp, err := c.client.Do(r)
+	resp, err := c.do(r)
 	if err != nil {
-		return nil, err
+		return nil, newRequestDispatchError(err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		fullBody, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return nil, fmt.Errorf("response status code %d", resp.StatusCode)
-		}
-		return nil, fmt.Errorf("response status code %d: %s", resp.StatusCode, fullBody)
+		return nil, handleErrorResponse(resp)
 	}
 
 	var factors []types.Factor
 	err = json.NewDecoder(resp.Body).Decode(&factors)
 	if err != nil {
-		return nil, err
+		return nil, newResponseDecodingError(err)
 	}
 
 	return &types.AdminListUserFactorsResponse{
@@ -65,32 +60,28 @@ func (c *Client) AdminUpdateUserFactor(
 
 	body, err := json.Marshal(req)
 	if err != nil {
-		return nil, err
+		return nil, newRequestEncodingError(err)
 	}
 
-	r, err := c.newRequest(ctx, path, http.MethodPut, bytes.NewBuffer(body))
+	r, err := c.newRequest(ctx, "AdminUpdateUserFactor", path, http.MethodPut, bytes.NewBuffer(body))
 	if err != nil {
-		return nil, err
+		return nil, newRequestCreationError(err)
 	}
 
-	resp, err := c.client.Do(r)
+	resp, err := c.do(r)
 	if err != nil {
-		return nil, err
+		return nil, newRequestDispatchError(err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		fullBody, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return nil, fmt.Errorf("response status code %d", resp.StatusCode)
-		}
-		return nil, fmt.Errorf("response status code %d: %s", resp.StatusCode, fullBody)
+		return nil, handleErrorResponse(resp)
 	}
 
 	var res types.AdminUpdateUserFactorResponse
 	err = json.NewDecoder(resp.Body).Decode(&res)
 	if err != nil {
-		return nil, err
+		return nil, newResponseDecodingError(err)
 	}
 
 	return &res, nil
@@ -102,23 +93,19 @@ func (c *Client) AdminUpdateUserFactor(
 func (c *Client) AdminDeleteUserFactor(ctx context.Context, req types.AdminDeleteUserFactorRequest) error {
 	path := fmt.Sprintf("%s/%s/factors/%s", adminUsersPath, req.UserID, req.FactorID)
 
-	r, err := c.newRequest(ctx, path, http.MethodDelete, nil)
+	r, err := c.newRequest(ctx, "AdminDeleteUserFactor", path, http.MethodDelete, nil)
 	if err != nil {
-		return err
+		return newRequestCreationError(err)
 	}
 
-	resp, err := c.client.Do(r)
+	resp, err := c.do(r)
 	if err != nil {
-		return err
+		return newRequestDispatchError(err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		fullBody, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return fmt.Errorf("response status code %d", resp.StatusCode)
-		}
-		return fmt.Errorf("response status code %d: %s", resp.StatusCode, fullBody)
+		return handleErrorResponse(resp)
 	}
 
 	return nil
@@ -0,0 +1,87 @@
+package endpoints
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/mrehanabbasi/supabase-auth-go/types"
+)
+
+// POST /signup
+//
+// Sign in as a new anonymous user. Anonymous sign-ins must be enabled on the
+// Auth server.
+//
+// The returned session belongs to a user with IsAnonymous set. Use
+// ConvertAnonymousUser to turn it into a permanent user, keeping its ID and
+// any data associated with it.
+func (c *Client) SignInAnonymously(ctx context.Context, req types.SignInAnonymouslyRequest) (*types.SignInAnonymouslyResponse, error) {
+	body := new(bytes.Buffer)
+	if err := json.NewEncoder(body).Encode(req); err != nil {
+		return nil, newRequestEncodingError(err)
+	}
+
+	r, err := c.newRequest(ctx, "SignInAnonymously", signupPath, http.MethodPost, body)
+	if err != nil {
+		return nil, newRequestCreationError(err)
+	}
+
+	resp, err := c.do(r)
+	if err != nil {
+		return nil, newRequestDispatchError(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, handleErrorResponse(resp)
+	}
+
+	var res types.SignInAnonymouslyResponse
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return nil, newResponseDecodingError(err)
+	}
+
+	c.emit(types.AuthChangeEventSignedIn, &res.Session)
+
+	if err := c.saveSession(ctx, res.Session); err != nil {
+		return &res, err
+	}
+
+	return &res, nil
+}
+
+// Convert an anonymous user into a permanent user
+//
+// This is a convenience method that checks the logged in user is anonymous,
+// then calls UpdateUser to attach an email or phone, and optionally a
+// password (requires authentication as the anonymous user).
+//
+// The Auth server sends a confirmation to the new email or phone. The user
+// keeps its ID, so data associated with the anonymous user is kept, and stops
+// being anonymous once the email or phone is verified.
+func (c *Client) ConvertAnonymousUser(ctx context.Context, req types.ConvertAnonymousUserRequest) (*types.UpdateUserResponse, error) {
+	if (req.Email == "") == (req.Phone == "") {
+		return nil, types.ErrInvalidConvertAnonymousRequest
+	}
+
+	user, err := c.GetUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsAnonymous {
+		return nil, types.ErrUserNotAnonymous
+	}
+
+	update := types.UpdateUserRequest{
+		Email: req.Email,
+		Phone: req.Phone,
+		Data:  req.Data,
+	}
+	if req.Password != "" {
+		update.Password = &req.Password
+	}
+
+	return c.UpdateUser(ctx, update)
+}
@@ -5,7 +5,6 @@ import (
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/base64"
-	"fmt"
 	"io"
 	"net/http"
 
@@ -42,53 +41,48 @@ func generatePKCEParams() (*types.PKCEParams, error) {
 // follow the redirect, but instead returns the URL the client was told to
 // redirect to.
 func (c *Client) Authorize(ctx context.Context, req types.AuthorizeRequest) (*types.AuthorizeResponse, error) {
-	r, err := c.newRequest(ctx, authorizePath, http.MethodGet, nil)
+	r, err := c.newRequest(ctx, "Authorize", authorizePath, http.MethodGet, nil)
 	if err != nil {
-		return nil, err
+		return nil, newRequestCreationError(err)
 	}
 
 	q := r.URL.Query()
 	q.Add("scopes", req.Scopes)
 	q.Add("provider", string(req.Provider))
-	q.Add("redirect_to", req.RedirectTo)
 
-	verifier := ""
+	verifier, err := addPKCEChallenge(q, req.FlowType)
+	if err != nil {
+		return nil, err
+	}
 
-	if string(req.FlowType) == string(types.FlowPKCE) {
-		pkce, err := generatePKCEParams()
-		if err != nil {
-			return nil, err
-		}
-		q.Add("code_challenge", pkce.Challenge)
-		q.Add("code_challenge_method", pkce.ChallengeMethod)
-		verifier = pkce.Verifier
+	state, redirectTo, err := c.storeVerifier(ctx, verifier, req.RedirectTo)
+	if err != nil {
+		return nil, err
 	}
+	q.Add("redirect_to", redirectTo)
 
 	r.URL.RawQuery = q.Encode()
 
 	// Set up a client that will not follow the redirect.
 	noRedirClient := noRedirClient(c.client)
 
-	resp, err := noRedirClient.Do(r)
+	resp, err := c.doWith(&noRedirClient, r)
 	if err != nil {
-		return nil, err
+		return nil, newRequestDispatchError(err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusFound {
-		fullBody, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return nil, fmt.Errorf("response status code %d", resp.StatusCode)
-		}
-		return nil, fmt.Errorf("response status code %d: %s", resp.StatusCode, fullBody)
+		return nil, handleErrorResponse(resp)
 	}
 
 	url := resp.Header.Get("Location")
 	if url == "" {
-		return nil, fmt.Errorf("no redirect URL found in response")
+		return nil, ErrRedirectURLNotInResponse
 	}
 	return &types.AuthorizeResponse{
 		AuthorizationURL: url,
 		Verifier:         verifier,
+		State:            state,
 	}, nil
 }
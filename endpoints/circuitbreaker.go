@@ -0,0 +1,256 @@
+package endpoints
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/mrehanabbasi/supabase-auth-go/types"
+)
+
+const (
+	defaultCircuitMinRequests = 10
+	defaultCircuitWindow      = time.Minute
+	defaultCircuitOpenTimeout = 30 * time.Second
+
+	// circuitWindowBuckets is how many parts the failure rate window is
+	// divided into. The oldest part is dropped as a whole.
+	circuitWindowBuckets = 10
+)
+
+// ErrCircuitOpen is returned, without sending the request, while the
+// client's circuit breaker is open.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// circuitBreaker enforces a CircuitBreakerPolicy. It is shared by the copies
+// of the client it was created for.
+type circuitBreaker struct {
+	policy types.CircuitBreakerPolicy
+
+	mu       sync.Mutex
+	state    types.CircuitState
+	openedAt time.Time
+	// probing is set while the probe of a half-open circuit is in flight.
+	probing     bool
+	consecutive int
+	window      [circuitWindowBuckets]circuitBucket
+}
+
+type circuitBucket struct {
+	start  time.Time
+	total  int
+	failed int
+}
+
+type circuitResult int
+
+const (
+	circuitSuccess circuitResult = iota
+	circuitFailure
+	// circuitIgnored is the result of requests cancelled by the caller.
+	circuitIgnored
+)
+
+func newCircuitBreaker(policy types.CircuitBreakerPolicy) *circuitBreaker {
+	if policy.MinRequests <= 0 {
+		policy.MinRequests = defaultCircuitMinRequests
+	}
+	if policy.Window <= 0 {
+		policy.Window = defaultCircuitWindow
+	}
+	if policy.OpenTimeout <= 0 {
+		policy.OpenTimeout = defaultCircuitOpenTimeout
+	}
+	return &circuitBreaker{
+		policy: policy,
+		state:  types.CircuitClosed,
+	}
+}
+
+func (b *circuitBreaker) State() types.CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// allow reports whether a request may be sent, and whether it is the probe
+// of a half-open circuit.
+func (b *circuitBreaker) allow() (probe bool, err error) {
+	b.mu.Lock()
+	changed := b.state
+
+	switch b.state {
+	case types.CircuitOpen:
+		if time.Since(b.openedAt) < b.policy.OpenTimeout {
+			err = ErrCircuitOpen
+			break
+		}
+		b.state = types.CircuitHalfOpen
+		fallthrough
+	case types.CircuitHalfOpen:
+		if b.probing {
+			err = ErrCircuitOpen
+			break
+		}
+		b.probing = true
+		probe = true
+	}
+
+	b.unlock(changed)
+	return probe, err
+}
+
+// done records the result of a request allowed by allow.
+func (b *circuitBreaker) done(probe bool, result circuitResult) {
+	b.mu.Lock()
+	changed := b.state
+	now := time.Now()
+
+	switch {
+	case probe:
+		b.probing = false
+		switch result {
+		case circuitSuccess:
+			b.state = types.CircuitClosed
+		case circuitFailure:
+			b.open(now)
+		}
+	case b.state != types.CircuitClosed || result == circuitIgnored:
+		// Requests sent before the circuit opened say nothing new.
+	default:
+		bucket := b.bucket(now)
+		bucket.total++
+		if result == circuitFailure {
+			bucket.failed++
+			b.consecutive++
+		} else {
+			b.consecutive = 0
+		}
+		if b.tripped(now) {
+			b.open(now)
+		}
+	}
+
+	b.unlock(changed)
+}
+
+// open opens the circuit, and forgets the failures that led to it.
+func (b *circuitBreaker) open(now time.Time) {
+	b.state = types.CircuitOpen
+	b.openedAt = now
+	b.consecutive = 0
+	b.window = [circuitWindowBuckets]circuitBucket{}
+}
+
+// tripped reports whether either of the policy's thresholds was reached.
+func (b *circuitBreaker) tripped(now time.Time) bool {
+	if b.policy.ConsecutiveFailures > 0 && b.consecutive >= b.policy.ConsecutiveFailures {
+		return true
+	}
+	if b.policy.FailureRate <= 0 {
+		return false
+	}
+	total, failed := 0, 0
+	for _, bucket := range b.window {
+		if now.Sub(bucket.start) < b.policy.Window {
+			total += bucket.total
+			failed += bucket.failed
+		}
+	}
+	return total >= b.policy.MinRequests && float64(failed) >= b.policy.FailureRate*float64(total)
+}
+
+// bucket returns the part of the failure rate window now falls into,
+// clearing it if it was last used for an earlier part.
+func (b *circuitBreaker) bucket(now time.Time) *circuitBucket {
+	width := b.policy.Window / circuitWindowBuckets
+	start := now.Truncate(width)
+	bucket := &b.window[(start.UnixNano()/int64(width))%circuitWindowBuckets]
+	if !bucket.start.Equal(start) {
+		*bucket = circuitBucket{start: start}
+	}
+	return bucket
+}
+
+// unlock releases the lock, and then calls OnStateChange if the state is no
+// longer from.
+func (b *circuitBreaker) unlock(from types.CircuitState) {
+	to := b.state
+	b.mu.Unlock()
+
+	if from != to && b.policy.OnStateChange != nil {
+		b.policy.OnStateChange(from, to)
+	}
+}
+
+// circuitResultOf classifies the outcome of a request to the Auth server.
+// Only network errors and 5xx responses are failures. Other errors, e.g. from
+// an interceptor that refused the request, say nothing about the Auth server
+// and are ignored.
+func circuitResultOf(ctx context.Context, resp *http.Response, err error) circuitResult {
+	var apiErr *APIError
+	var decodingErr *ResponseDecodingError
+	var dispatchErr *RequestDispatchError
+	var netErr net.Error
+	switch {
+	case err != nil && errors.Is(ctx.Err(), context.Canceled):
+		return circuitIgnored
+	case errors.As(err, &apiErr):
+		if apiErr.StatusCode >= 500 {
+			return circuitFailure
+		}
+		// The Auth server responded, even if not as hoped.
+		return circuitSuccess
+	case errors.As(err, &decodingErr):
+		return circuitSuccess
+	case errors.As(err, &netErr), errors.As(err, &dispatchErr):
+		return circuitFailure
+	case err != nil:
+		return circuitIgnored
+	case resp != nil && resp.StatusCode >= 500:
+		return circuitFailure
+	}
+	return circuitSuccess
+}
+
+// circuit sends r with send unless the client's circuit breaker is open.
+func (c *Client) circuit(endpoint string, r *http.Request, send func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	ctx := r.Context()
+	probe, err := c.breaker.allow()
+	if err != nil {
+		return nil, err
+	}
+
+	if probe && c.breaker.policy.ProbeWithHealthCheck && endpoint != "HealthCheck" {
+		// Probe without the breaker and rate limiter, which would refuse.
+		unguarded := *c
+		unguarded.breaker = nil
+		unguarded.rateLimiter = nil
+		_, err := unguarded.HealthCheck(ctx)
+		// HealthCheck wraps whatever stopped it in a RequestDispatchError.
+		var dispatchErr *RequestDispatchError
+		if errors.As(err, &dispatchErr) {
+			err = dispatchErr.Err
+		}
+		switch result := circuitResultOf(ctx, nil, err); {
+		case result == circuitFailure:
+			c.breaker.done(true, result)
+			return nil, ErrCircuitOpen
+		case result == circuitIgnored && ctx.Err() != nil:
+			c.breaker.done(true, result)
+			return nil, ctx.Err()
+		case result == circuitSuccess:
+			c.breaker.done(true, result)
+			probe = false
+		}
+		// If the probe was refused before reaching the Auth server, e.g. by
+		// an interceptor, the call itself is the probe.
+	}
+
+	resp, err := send(r)
+	c.breaker.done(probe, circuitResultOf(ctx, resp, err))
+	return resp, err
+}
@@ -0,0 +1,212 @@
+package endpoints_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrehanabbasi/supabase-auth-go/endpoints"
+	"github.com/mrehanabbasi/supabase-auth-go/types"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	ctx := context.Background()
+
+	var healthy atomic.Bool
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	var mu sync.Mutex
+	var changes []types.CircuitState
+	client := endpoints.New("", "api_key").WithCustomAuthURL(srv.URL).WithCircuitBreaker(types.CircuitBreakerPolicy{
+		ConsecutiveFailures: 3,
+		OpenTimeout:         50 * time.Millisecond,
+		OnStateChange: func(from, to types.CircuitState) {
+			mu.Lock()
+			defer mu.Unlock()
+			changes = append(changes, to)
+		},
+	})
+	assert.Equal(types.CircuitClosed, client.CircuitState())
+
+	for range 3 {
+		_, err := client.GetSettings(ctx)
+		require.Error(err)
+		assert.NotErrorIs(err, endpoints.ErrCircuitOpen)
+	}
+	assert.Equal(types.CircuitOpen, client.CircuitState())
+
+	// While open, copies of the client fail without sending anything.
+	_, err := client.WithToken("token").GetUser(ctx)
+	assert.ErrorIs(err, endpoints.ErrCircuitOpen)
+	assert.True(endpoints.IsRetryable(err))
+	assert.Equal(int32(3), calls.Load())
+
+	// The first call after the timeout is the probe, and reopens the
+	// circuit if it fails.
+	time.Sleep(60 * time.Millisecond)
+	_, err = client.HealthCheck(ctx)
+	require.Error(err)
+	assert.NotErrorIs(err, endpoints.ErrCircuitOpen)
+	assert.Equal(types.CircuitOpen, client.CircuitState())
+	assert.Equal(int32(4), calls.Load())
+
+	healthy.Store(true)
+	time.Sleep(60 * time.Millisecond)
+	_, err = client.HealthCheck(ctx)
+	require.NoError(err)
+	assert.Equal(types.CircuitClosed, client.CircuitState())
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal([]types.CircuitState{
+		types.CircuitOpen,
+		types.CircuitHalfOpen, types.CircuitOpen,
+		types.CircuitHalfOpen, types.CircuitClosed,
+	}, changes)
+}
+
+func TestCircuitBreakerHealthCheckProbe(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	ctx := context.Background()
+
+	var healthy atomic.Bool
+	var paths []string
+	var mu sync.Mutex
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+		switch {
+		case !healthy.Load():
+			w.WriteHeader(http.StatusBadGateway)
+		case r.URL.Path == "/token":
+			// Client errors do not count as failures.
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"code":400,"error_code":"invalid_credentials","msg":"Invalid login credentials"}`))
+		default:
+			_, _ = w.Write([]byte(`{}`))
+		}
+	}))
+	defer srv.Close()
+
+	client := endpoints.New("", "api_key").WithCustomAuthURL(srv.URL).WithCircuitBreaker(types.CircuitBreakerPolicy{
+		FailureRate:          0.5,
+		MinRequests:          4,
+		OpenTimeout:          50 * time.Millisecond,
+		ProbeWithHealthCheck: true,
+	})
+
+	healthy.Store(true)
+	for range 2 {
+		_, err := client.SignInWithEmailPassword(ctx, "user@test.com", "password")
+		assert.ErrorIs(err, endpoints.ErrInvalidCredentials)
+	}
+	healthy.Store(false)
+	_, err := client.GetSettings(ctx)
+	require.Error(err)
+	assert.Equal(types.CircuitClosed, client.CircuitState())
+	_, err = client.GetSettings(ctx)
+	require.Error(err)
+	assert.Equal(types.CircuitOpen, client.CircuitState())
+
+	// A failed health check keeps the circuit open, without sending the call.
+	time.Sleep(60 * time.Millisecond)
+	_, err = client.GetSettings(ctx)
+	assert.ErrorIs(err, endpoints.ErrCircuitOpen)
+	assert.Equal(types.CircuitOpen, client.CircuitState())
+
+	// A passing one closes it, and the call goes through.
+	healthy.Store(true)
+	time.Sleep(60 * time.Millisecond)
+	_, err = client.GetSettings(ctx)
+	require.NoError(err)
+	assert.Equal(types.CircuitClosed, client.CircuitState())
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal([]string{"/token", "/token", "/settings", "/settings", "/health", "/health", "/settings"}, paths)
+}
+
+func TestCircuitBreakerInterceptorRefusal(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	ctx := context.Background()
+
+	var healthy atomic.Bool
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	errRefused := errors.New("refused")
+	var refuse atomic.Bool
+	client := endpoints.New("", "api_key").
+		WithCustomAuthURL(srv.URL).
+		WithCircuitBreaker(types.CircuitBreakerPolicy{
+			ConsecutiveFailures:  3,
+			OpenTimeout:          50 * time.Millisecond,
+			ProbeWithHealthCheck: true,
+		}).
+		WithInterceptors(func(endpoint string, req *http.Request, next types.RoundTrip) (*http.Response, error) {
+			if refuse.Load() || endpoint == "HealthCheck" {
+				return nil, errRefused
+			}
+			return next(req)
+		})
+
+	// Requests refused by an interceptor never reach the Auth server, so they
+	// do not trip the breaker.
+	refuse.Store(true)
+	for range 5 {
+		_, err := client.GetSettings(ctx)
+		assert.ErrorIs(err, errRefused)
+	}
+	assert.Equal(types.CircuitClosed, client.CircuitState())
+	assert.EqualValues(0, calls.Load())
+
+	refuse.Store(false)
+	for range 3 {
+		_, err := client.GetSettings(ctx)
+		require.Error(err)
+	}
+	assert.Equal(types.CircuitOpen, client.CircuitState())
+
+	// If the health check is refused, the call itself is the probe.
+	healthy.Store(true)
+	time.Sleep(60 * time.Millisecond)
+	_, err := client.GetSettings(ctx)
+	require.NoError(err)
+	assert.Equal(types.CircuitClosed, client.CircuitState())
+	assert.EqualValues(4, calls.Load())
+}
+
+func TestCircuitBreakerDisabled(t *testing.T) {
+	client := endpoints.New("", "api_key").WithCircuitBreaker(types.CircuitBreakerPolicy{OpenTimeout: time.Second})
+	assert.Equal(t, types.CircuitClosed, client.CircuitState())
+}
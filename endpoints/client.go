@@ -2,8 +2,12 @@ package endpoints
 
 import (
 	"fmt"
+	"log/slog"
 	"net/http"
+	"slices"
 	"time"
+
+	"github.com/mrehanabbasi/supabase-auth-go/types"
 )
 
 type Client struct {
@@ -11,6 +15,22 @@ type Client struct {
 	baseURL string
 	apiKey  string
 	token   string
+
+	sessionStore types.SessionStore
+	sessionKey   string
+
+	verifierStore types.VerifierStore
+
+	retryPolicy  types.RetryPolicy
+	interceptors []types.Interceptor
+	logger       *slog.Logger
+	observer     types.Observer
+
+	passwordPolicy *types.PasswordPolicy
+	rateLimiter    *rateLimiter
+	breaker        *circuitBreaker
+
+	events *eventBus
 }
 
 func New(projectReference string, apiKey string) *Client {
@@ -21,34 +41,114 @@ func New(projectReference string, apiKey string) *Client {
 		},
 		baseURL: baseURL,
 		apiKey:  apiKey,
+		events:  newEventBus(),
 	}
 }
 
 func (c Client) WithCustomAuthURL(url string) *Client {
-	return &Client{
-		client:  c.client,
-		baseURL: url,
-		apiKey:  c.apiKey,
-		token:   c.token,
-	}
+	c.baseURL = url
+	return &c
 }
 
 func (c Client) WithToken(token string) *Client {
-	return &Client{
-		client:  c.client,
-		baseURL: c.baseURL,
-		apiKey:  c.apiKey,
-		token:   token,
-	}
+	c.token = token
+	return &c
 }
 
 func (c Client) WithClient(client *http.Client) *Client {
-	return &Client{
-		client:  client,
-		baseURL: c.baseURL,
-		apiKey:  c.apiKey,
-		token:   c.token,
+	c.client = client
+	return &c
+}
+
+// WithSessionStore returns a copy of the client that persists sessions
+// returned by the Auth server into store under key. If key is empty,
+// types.DefaultSessionKey is used.
+func (c Client) WithSessionStore(store types.SessionStore, key string) *Client {
+	if key == "" {
+		key = types.DefaultSessionKey
+	}
+	c.sessionStore = store
+	c.sessionKey = key
+	return &c
+}
+
+// WithVerifierStore returns a copy of the client that saves the PKCE code
+// verifier of every flow it starts into store, under a random state. The
+// state is returned in the response and added to the redirect URL as the
+// types.StateParam query parameter, and ExchangeCodeForSession uses it to
+// look up the verifier.
+func (c Client) WithVerifierStore(store types.VerifierStore) *Client {
+	c.verifierStore = store
+	return &c
+}
+
+// WithRetryPolicy returns a copy of the client that retries requests failing
+// with a transient error according to policy.
+func (c Client) WithRetryPolicy(policy types.RetryPolicy) *Client {
+	c.retryPolicy = policy
+	return &c
+}
+
+// WithInterceptors returns a copy of the client that passes every request
+// through interceptors, after those the client already has. The first
+// interceptor added is the outermost.
+func (c Client) WithInterceptors(interceptors ...types.Interceptor) *Client {
+	c.interceptors = append(slices.Clip(c.interceptors), interceptors...)
+	return &c
+}
+
+// WithLogger returns a copy of the client that logs every request to logger.
+// Passing nil disables logging.
+func (c Client) WithLogger(logger *slog.Logger) *Client {
+	c.logger = logger
+	return &c
+}
+
+// WithObserver returns a copy of the client that reports a span, a count
+// and the latency of every request to observer. Passing nil disables it.
+func (c Client) WithObserver(observer types.Observer) *Client {
+	c.observer = observer
+	return &c
+}
+
+// WithPasswordPolicy returns a copy of the client that checks new passwords
+// passed to Signup, UpdateUser and AdminCreateUser against policy, and
+// returns a *WeakPasswordError without sending the request if they break it.
+func (c Client) WithPasswordPolicy(policy types.PasswordPolicy) *Client {
+	c.passwordPolicy = &policy
+	return &c
+}
+
+// WithRateLimitPolicy returns a copy of the client that limits its requests
+// according to policy. The limits are shared with copies made from the
+// returned client, but start afresh each time WithRateLimitPolicy is called.
+func (c Client) WithRateLimitPolicy(policy types.RateLimitPolicy) *Client {
+	c.rateLimiter = nil
+	if len(policy.Endpoints) > 0 || policy.RecipientCooldown > 0 {
+		c.rateLimiter = newRateLimiter(policy)
+	}
+	return &c
+}
+
+// WithCircuitBreaker returns a copy of the client that stops sending requests
+// while the Auth server is failing, according to policy, and returns
+// ErrCircuitOpen instead. The circuit is shared with copies made from the
+// returned client, but starts closed each time WithCircuitBreaker is called.
+func (c Client) WithCircuitBreaker(policy types.CircuitBreakerPolicy) *Client {
+	c.breaker = nil
+	if policy.ConsecutiveFailures > 0 || policy.FailureRate > 0 {
+		c.breaker = newCircuitBreaker(policy)
+	}
+	return &c
+}
+
+// CircuitState returns the state of the client's circuit breaker. It is
+// always closed if the client has none.
+func (c *Client) CircuitState() types.CircuitState {
+	if c.breaker == nil {
+		return types.CircuitClosed
 	}
+	return c.breaker.State()
 }
 
 // Returns a copy of a HTTP client that will not follow redirects.
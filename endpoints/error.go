@@ -4,22 +4,21 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"slices"
+	"strings"
 )
 
-var (
-	errCodeUserAlreadyExists          = "user_already_exists"
-	errCodeInvalidCredentials         = "invalid_credentials"
-	errCodeSessionNotFound            = "session_not_found"
-	errCodeBadJWT                     = "bad_jwt"
-	errCodeEmailNotConfirmed          = "email_not_confirmed"
-	errCodeUnexpectedFailure          = "unexpected_failure"
-	errCodeEmailSendRateLimitExceeded = "over_email_send_rate_limit"
-	errCodeNoAuthorization            = "no_authorization"
-	errCodeNotAdmin                   = "not_admin"
-	errCodeValidationFailed           = "validation_failed"
-	errCodeRefreshTokenNotFound       = "refresh_token_not_found"
+const (
+	// maxErrorBody is the most of an error response body that is read.
+	maxErrorBody = 64 << 10
+	// maxErrorSnippet is the most of an error response body that is not
+	// JSON kept as the message.
+	maxErrorSnippet = 256
+)
 
+var (
 	errMsgErrorSendingConfirmationEmail = "Error sending confirmation email"
 	errMsguserIDMustBeUUID              = "user_id must be an UUID"
 
@@ -35,18 +34,6 @@ var (
 	ErrNotAdmin                       = errors.New("not admin")
 	ErrInvalidUserID                  = errors.New("invalid user id")
 	ErrRefreshTokenNotFound           = errors.New("refresh token not found")
-
-	distinctErrors = map[string]error{
-		errCodeUserAlreadyExists:          ErrUserAlreadyExists,
-		errCodeInvalidCredentials:         ErrInvalidCredentials,
-		errCodeSessionNotFound:            ErrSessionNotFound,
-		errCodeBadJWT:                     ErrInvalidJWT,
-		errCodeEmailNotConfirmed:          ErrEmailNotConfirmed,
-		errCodeEmailSendRateLimitExceeded: ErrEmailSendLimitExceeded,
-		errCodeNoAuthorization:            ErrNoAuthorization,
-		errCodeNotAdmin:                   ErrNotAdmin,
-		errCodeRefreshTokenNotFound:       ErrRefreshTokenNotFound,
-	}
 )
 
 type WeakPasswordReason string
@@ -54,7 +41,10 @@ type WeakPasswordReason string
 const (
 	WeakPasswordReasonLength     = "length"
 	WeakPasswordReasonCharacters = "characters"
-	WeakPasswordReasonsPwned     = "pwned"
+	WeakPasswordReasonPwned      = "pwned"
+
+	// Deprecated: Use WeakPasswordReasonPwned.
+	WeakPasswordReasonsPwned = WeakPasswordReasonPwned
 )
 
 type ErrorResponse struct {
@@ -83,61 +73,231 @@ func (e ErrorResponse) Error() string {
 
 func (e ErrorResponse) getDistinctError() error {
 	if e.ErrorCode == nil {
-		if *e.ErrorCode == errCodeUnexpectedFailure &&
-			e.Message != nil && *e.Message == errMsgErrorSendingConfirmationEmail {
-			return ErrFailedSendingConfirmationEmail
-		}
+		return nil
+	}
+	code := ErrorCode(*e.ErrorCode)
+
+	if code == ErrorCodeUnexpectedFailure &&
+		e.Message != nil && *e.Message == errMsgErrorSendingConfirmationEmail {
+		return ErrFailedSendingConfirmationEmail
+	}
+	if code == ErrorCodeValidationFailed &&
+		e.Message != nil && *e.Message == errMsguserIDMustBeUUID {
+		return ErrInvalidUserID
+	}
+
+	return distinctErrors[code]
+}
+
+// APIError is returned by every method when the Auth server responds with an
+// error status. It wraps the matching sentinel error, e.g.
+// ErrInvalidCredentials, if there is one, so errors.Is can be used to check
+// for specific errors, and the decoded ErrorResponse.
+type APIError struct {
+	// StatusCode is the HTTP status code of the response, e.g. 400.
+	StatusCode int
+	// Status is the HTTP status of the response, e.g. "400 Bad Request".
+	Status string
+	// ErrorCode is the error_code of the response, or the OAuth error if it
+	// has none. It is empty for responses from older Auth servers, and for
+	// error pages served by proxies.
+	ErrorCode ErrorCode
+	// Message is the human readable message of the response. For responses
+	// that are not JSON, such as error pages served by proxies, it is the
+	// start of the body.
+	Message string
+	// RequestID is the request ID the Auth server or the Supabase platform
+	// assigned to the request, if any. Include it when reporting issues.
+	RequestID string
+	// WeakPasswordReasons explains why a password was rejected as too weak.
+	WeakPasswordReasons []WeakPasswordReason
+	// Response is the decoded response body.
+	Response ErrorResponse
+
+	sentinels []error
+}
 
-		if *e.ErrorCode == errCodeValidationFailed &&
-			e.Message != nil && *e.Message == errMsguserIDMustBeUUID {
-			return ErrInvalidUserID
-		}
+func (e *APIError) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = string(e.ErrorCode)
+	}
+	if msg == "" {
+		return "supabase-auth - " + e.Status
 	}
+	return fmt.Sprintf("supabase-auth - %s: %s", e.Status, msg)
+}
 
-	for k, v := range distinctErrors {
-		if e.ErrorCode != nil {
-			if k == *e.ErrorCode {
-				return v
-			}
-		}
+func (e *APIError) Unwrap() []error {
+	errs := append(slices.Clip(e.sentinels), e.Response)
+	if len(e.WeakPasswordReasons) > 0 {
+		errs = append(errs, &WeakPasswordError{Message: e.Message, Reasons: e.WeakPasswordReasons})
 	}
+	return errs
+}
+
+// RequestEncodingError is returned when a request body cannot be encoded.
+type RequestEncodingError struct {
+	Err error
+}
+
+func (e *RequestEncodingError) Error() string {
+	return "failed to encode request body: " + e.Err.Error()
+}
 
-	return nil
+func (e *RequestEncodingError) Unwrap() error {
+	return e.Err
+}
+
+// RequestCreationError is returned when a request cannot be created, e.g.
+// because the auth URL is invalid.
+type RequestCreationError struct {
+	Err error
+}
+
+func (e *RequestCreationError) Error() string {
+	return "failed to create request: " + e.Err.Error()
+}
+
+func (e *RequestCreationError) Unwrap() error {
+	return e.Err
+}
+
+// RequestDispatchError is returned when a request gets no response, e.g.
+// because of a network error or the context being cancelled.
+type RequestDispatchError struct {
+	Err error
+}
+
+func (e *RequestDispatchError) Error() string {
+	return "failed to send request: " + e.Err.Error()
+}
+
+func (e *RequestDispatchError) Unwrap() error {
+	return e.Err
+}
+
+// ResponseDecodingError is returned when a successful response body cannot be
+// decoded.
+type ResponseDecodingError struct {
+	Err error
+}
+
+func (e *ResponseDecodingError) Error() string {
+	return "failed to decode response body: " + e.Err.Error()
+}
+
+func (e *ResponseDecodingError) Unwrap() error {
+	return e.Err
 }
 
 func newRequestEncodingError(err error) error {
-	return fmt.Errorf("failed to encode request body: %w", err)
+	return &RequestEncodingError{Err: err}
 }
 
 func newRequestCreationError(err error) error {
-	return fmt.Errorf("failed to create request: %w", err)
+	return &RequestCreationError{Err: err}
 }
 
 func newRequestDispatchError(err error) error {
-	return fmt.Errorf("failed to send request: %w", err)
+	return &RequestDispatchError{Err: err}
 }
 
 func newResponseDecodingError(err error) error {
-	return fmt.Errorf("failed to decode response body: %w", err)
+	return &ResponseDecodingError{Err: err}
 }
 
-func newErrorResponseDecodingError(status string, err error) error {
-	return fmt.Errorf("failed to decode error response body (%s): %w", status, err)
+func newSessionStoreError(err error) error {
+	return fmt.Errorf("failed to persist session: %w", err)
 }
 
-func wrapError(status string, err error) error {
-	return fmt.Errorf("supabase-auth - %s: %w", status, err)
+func newVerifierStoreError(err error) error {
+	return fmt.Errorf("failed to access code verifier: %w", err)
+}
+
+// snippet returns the start of body, with runs of whitespace collapsed, to
+// show as the message of an error response that is not JSON.
+func snippet(body []byte) string {
+	msg := strings.Join(strings.Fields(string(body)), " ")
+	if len(msg) <= maxErrorSnippet {
+		return msg
+	}
+	return strings.ToValidUTF8(msg[:maxErrorSnippet], "") + "..."
+}
+
+// newLocalAPIError returns the APIError the Auth server would respond with,
+// for requests the client refuses without sending them.
+func newLocalAPIError(statusCode int, code ErrorCode, message string) *APIError {
+	errorCode := string(code)
+	errRes := ErrorResponse{
+		Code:      &statusCode,
+		Message:   &message,
+		ErrorCode: &errorCode,
+	}
+	apiErr := &APIError{
+		StatusCode: statusCode,
+		Status:     fmt.Sprintf("%d %s", statusCode, http.StatusText(statusCode)),
+		ErrorCode:  code,
+		Message:    message,
+		Response:   errRes,
+	}
+	if sentinel := errRes.getDistinctError(); sentinel != nil {
+		apiErr.sentinels = []error{sentinel}
+	}
+	return apiErr
+}
+
+// requestID returns the ID assigned to the request by the Auth server, or by
+// the Supabase API gateway in front of it.
+func requestID(resp *http.Response) string {
+	if id := resp.Header.Get("X-Request-Id"); id != "" {
+		return id
+	}
+	return resp.Header.Get("Sb-Request-Id")
 }
 
 func handleErrorResponse(resp *http.Response) error {
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		RequestID:  requestID(resp),
+	}
+	if apiErr.Status == "" {
+		apiErr.Status = fmt.Sprintf("%d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	if err != nil {
+		return apiErr
+	}
+	// Error pages served by proxies may not be JSON, in which case the start
+	// of the page is kept as the message.
 	var errRes ErrorResponse
-	if err := json.NewDecoder(resp.Body).Decode(&errRes); err != nil {
-		return newErrorResponseDecodingError(resp.Status, err)
+	if err := json.Unmarshal(data, &errRes); err != nil {
+		apiErr.Message = snippet(data)
+		return apiErr
 	}
 
-	if err := errRes.getDistinctError(); err != nil {
-		return err
+	apiErr.Response = errRes
+	switch {
+	case errRes.Message != nil:
+		apiErr.Message = *errRes.Message
+	case errRes.ErrDesc != nil:
+		apiErr.Message = *errRes.ErrDesc
+	case errRes.Err != nil:
+		apiErr.Message = *errRes.Err
+	}
+	if errRes.ErrorCode != nil {
+		apiErr.ErrorCode = ErrorCode(*errRes.ErrorCode)
+	} else if errRes.Err != nil {
+		apiErr.ErrorCode = ErrorCode(*errRes.Err)
+	}
+	if errRes.WeakPassword != nil {
+		apiErr.WeakPasswordReasons = errRes.WeakPassword.Reasons
+	}
+	if sentinel := errRes.getDistinctError(); sentinel != nil {
+		apiErr.sentinels = []error{sentinel}
 	}
 
-	return wrapError(resp.Status, errRes)
+	return apiErr
 }
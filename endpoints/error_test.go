@@ -0,0 +1,96 @@
+package endpoints_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrehanabbasi/supabase-auth-go/endpoints"
+	"github.com/mrehanabbasi/supabase-auth-go/types"
+)
+
+func TestAPIError(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	ctx := context.Background()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "req-1")
+		switch r.URL.Path {
+		case "/admin/users":
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"code":403,"error_code":"not_admin","msg":"User not allowed"}`))
+		case "/signup":
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			_, _ = w.Write([]byte(`{"code":422,"error_code":"weak_password","msg":"Password is too weak","weak_password":{"reasons":["length","pwned"]}}`))
+		case "/settings":
+			w.WriteHeader(http.StatusBadGateway)
+			_, _ = w.Write([]byte(`<html>Bad Gateway</html>`))
+		case "/user":
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte("upstream connect error\n\t" + strings.Repeat("x", 1000)))
+		case "/health":
+			_, _ = w.Write([]byte(`not json`))
+		}
+	}))
+	defer srv.Close()
+
+	client := endpoints.New("", "api_key").WithCustomAuthURL(srv.URL)
+
+	// Admin endpoints match the sentinel errors too.
+	_, err := client.AdminListUsers(ctx, types.AdminListUsersRequest{})
+	assert.ErrorIs(err, endpoints.ErrNotAdmin)
+	var apiErr *endpoints.APIError
+	require.ErrorAs(err, &apiErr)
+	assert.Equal(http.StatusForbidden, apiErr.StatusCode)
+	assert.Equal(endpoints.ErrorCodeNotAdmin, apiErr.ErrorCode)
+	assert.Equal("User not allowed", apiErr.Message)
+	assert.Equal("req-1", apiErr.RequestID)
+	var errRes endpoints.ErrorResponse
+	assert.ErrorAs(err, &errRes)
+
+	_, err = client.Signup(ctx, types.SignupRequest{Email: "user@test.com", Password: "pass"})
+	require.ErrorAs(err, &apiErr)
+	assert.Equal(endpoints.ErrorCodeWeakPassword, apiErr.ErrorCode)
+	assert.ErrorIs(err, endpoints.ErrWeakPassword)
+	assert.Equal([]endpoints.WeakPasswordReason{"length", "pwned"}, apiErr.WeakPasswordReasons)
+	assert.EqualError(err, "supabase-auth - 422 Unprocessable Entity: Password is too weak")
+	var weakErr *endpoints.WeakPasswordError
+	require.ErrorAs(err, &weakErr)
+	assert.Equal([]endpoints.WeakPasswordReason{endpoints.WeakPasswordReasonLength, endpoints.WeakPasswordReasonPwned}, weakErr.Reasons)
+
+	// Error pages that are not JSON still give the status, and what they say.
+	_, err = client.GetSettings(ctx)
+	require.ErrorAs(err, &apiErr)
+	assert.Equal(http.StatusBadGateway, apiErr.StatusCode)
+	assert.Empty(apiErr.ErrorCode)
+	assert.Equal("<html>Bad Gateway</html>", apiErr.Message)
+	assert.EqualError(err, "supabase-auth - 502 Bad Gateway: <html>Bad Gateway</html>")
+
+	// Long pages are cut short.
+	_, err = client.GetUser(ctx)
+	require.ErrorAs(err, &apiErr)
+	assert.Equal(http.StatusServiceUnavailable, apiErr.StatusCode)
+	assert.Equal("upstream connect error "+strings.Repeat("x", 233)+"...", apiErr.Message)
+
+	// Other failures have their own types.
+	_, err = client.HealthCheck(ctx)
+	var decodingErr *endpoints.ResponseDecodingError
+	assert.ErrorAs(err, &decodingErr)
+	assert.False(errors.As(err, &apiErr))
+
+	_, err = endpoints.New("", "api_key").WithCustomAuthURL("http://auth.invalid\x7f").HealthCheck(ctx)
+	var creationErr *endpoints.RequestCreationError
+	assert.ErrorAs(err, &creationErr)
+
+	srv.Close()
+	_, err = client.HealthCheck(ctx)
+	var dispatchErr *endpoints.RequestDispatchError
+	assert.ErrorAs(err, &dispatchErr)
+}
@@ -0,0 +1,324 @@
+package endpoints
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// ErrorCode is the error_code returned by the Auth server in error responses.
+// See APIError.
+type ErrorCode string
+
+const (
+	ErrorCodeUnexpectedFailure                 ErrorCode = "unexpected_failure"
+	ErrorCodeValidationFailed                  ErrorCode = "validation_failed"
+	ErrorCodeBadJSON                           ErrorCode = "bad_json"
+	ErrorCodeEmailExists                       ErrorCode = "email_exists"
+	ErrorCodePhoneExists                       ErrorCode = "phone_exists"
+	ErrorCodeBadJWT                            ErrorCode = "bad_jwt"
+	ErrorCodeNotAdmin                          ErrorCode = "not_admin"
+	ErrorCodeNoAuthorization                   ErrorCode = "no_authorization"
+	ErrorCodeUserNotFound                      ErrorCode = "user_not_found"
+	ErrorCodeSessionNotFound                   ErrorCode = "session_not_found"
+	ErrorCodeSessionExpired                    ErrorCode = "session_expired"
+	ErrorCodeRefreshTokenNotFound              ErrorCode = "refresh_token_not_found"
+	ErrorCodeRefreshTokenAlreadyUsed           ErrorCode = "refresh_token_already_used"
+	ErrorCodeFlowStateNotFound                 ErrorCode = "flow_state_not_found"
+	ErrorCodeFlowStateExpired                  ErrorCode = "flow_state_expired"
+	ErrorCodeSignupDisabled                    ErrorCode = "signup_disabled"
+	ErrorCodeUserBanned                        ErrorCode = "user_banned"
+	ErrorCodeProviderEmailNeedsVerification    ErrorCode = "provider_email_needs_verification"
+	ErrorCodeInviteNotFound                    ErrorCode = "invite_not_found"
+	ErrorCodeBadOAuthState                     ErrorCode = "bad_oauth_state"
+	ErrorCodeBadOAuthCallback                  ErrorCode = "bad_oauth_callback"
+	ErrorCodeOAuthProviderNotSupported         ErrorCode = "oauth_provider_not_supported"
+	ErrorCodeUnexpectedAudience                ErrorCode = "unexpected_audience"
+	ErrorCodeSingleIdentityNotDeletable        ErrorCode = "single_identity_not_deletable"
+	ErrorCodeEmailConflictIdentityNotDeletable ErrorCode = "email_conflict_identity_not_deletable"
+	ErrorCodeIdentityAlreadyExists             ErrorCode = "identity_already_exists"
+	ErrorCodeEmailProviderDisabled             ErrorCode = "email_provider_disabled"
+	ErrorCodePhoneProviderDisabled             ErrorCode = "phone_provider_disabled"
+	ErrorCodeTooManyEnrolledMFAFactors         ErrorCode = "too_many_enrolled_mfa_factors"
+	ErrorCodeMFAFactorNameConflict             ErrorCode = "mfa_factor_name_conflict"
+	ErrorCodeMFAFactorNotFound                 ErrorCode = "mfa_factor_not_found"
+	ErrorCodeMFAIPAddressMismatch              ErrorCode = "mfa_ip_address_mismatch"
+	ErrorCodeMFAChallengeExpired               ErrorCode = "mfa_challenge_expired"
+	ErrorCodeMFAVerificationFailed             ErrorCode = "mfa_verification_failed"
+	ErrorCodeMFAVerificationRejected           ErrorCode = "mfa_verification_rejected"
+	ErrorCodeMFAVerifiedFactorExists           ErrorCode = "mfa_verified_factor_exists"
+	ErrorCodeMFATOTPEnrollNotEnabled           ErrorCode = "mfa_totp_enroll_not_enabled"
+	ErrorCodeMFATOTPVerifyNotEnabled           ErrorCode = "mfa_totp_verify_not_enabled"
+	ErrorCodeMFAPhoneEnrollNotEnabled          ErrorCode = "mfa_phone_enroll_not_enabled"
+	ErrorCodeMFAPhoneVerifyNotEnabled          ErrorCode = "mfa_phone_verify_not_enabled"
+	ErrorCodeMFAWebAuthnEnrollNotEnabled       ErrorCode = "mfa_web_authn_enroll_not_enabled"
+	ErrorCodeMFAWebAuthnVerifyNotEnabled       ErrorCode = "mfa_web_authn_verify_not_enabled"
+	ErrorCodeInsufficientAAL                   ErrorCode = "insufficient_aal"
+	ErrorCodeCaptchaFailed                     ErrorCode = "captcha_failed"
+	ErrorCodeSAMLProviderDisabled              ErrorCode = "saml_provider_disabled"
+	ErrorCodeManualLinkingDisabled             ErrorCode = "manual_linking_disabled"
+	ErrorCodeSMSSendFailed                     ErrorCode = "sms_send_failed"
+	ErrorCodeEmailNotConfirmed                 ErrorCode = "email_not_confirmed"
+	ErrorCodePhoneNotConfirmed                 ErrorCode = "phone_not_confirmed"
+	ErrorCodeReauthNonceMissing                ErrorCode = "reauth_nonce_missing"
+	ErrorCodeSAMLRelayStateNotFound            ErrorCode = "saml_relay_state_not_found"
+	ErrorCodeSAMLRelayStateExpired             ErrorCode = "saml_relay_state_expired"
+	ErrorCodeSAMLIdPNotFound                   ErrorCode = "saml_idp_not_found"
+	ErrorCodeSAMLAssertionNoUserID             ErrorCode = "saml_assertion_no_user_id"
+	ErrorCodeSAMLAssertionNoEmail              ErrorCode = "saml_assertion_no_email"
+	ErrorCodeUserAlreadyExists                 ErrorCode = "user_already_exists"
+	ErrorCodeSSOProviderNotFound               ErrorCode = "sso_provider_not_found"
+	ErrorCodeSAMLMetadataFetchFailed           ErrorCode = "saml_metadata_fetch_failed"
+	ErrorCodeSAMLIdPAlreadyExists              ErrorCode = "saml_idp_already_exists"
+	ErrorCodeSSODomainAlreadyExists            ErrorCode = "sso_domain_already_exists"
+	ErrorCodeSAMLEntityIDMismatch              ErrorCode = "saml_entity_id_mismatch"
+	ErrorCodeConflict                          ErrorCode = "conflict"
+	ErrorCodeProviderDisabled                  ErrorCode = "provider_disabled"
+	ErrorCodeUserSSOManaged                    ErrorCode = "user_sso_managed"
+	ErrorCodeReauthenticationNeeded            ErrorCode = "reauthentication_needed"
+	ErrorCodeSamePassword                      ErrorCode = "same_password"
+	ErrorCodeReauthenticationNotValid          ErrorCode = "reauthentication_not_valid"
+	ErrorCodeOTPExpired                        ErrorCode = "otp_expired"
+	ErrorCodeOTPDisabled                       ErrorCode = "otp_disabled"
+	ErrorCodeIdentityNotFound                  ErrorCode = "identity_not_found"
+	ErrorCodeWeakPassword                      ErrorCode = "weak_password"
+	ErrorCodeOverRequestRateLimit              ErrorCode = "over_request_rate_limit"
+	ErrorCodeOverEmailSendRateLimit            ErrorCode = "over_email_send_rate_limit"
+	ErrorCodeOverSMSSendRateLimit              ErrorCode = "over_sms_send_rate_limit"
+	ErrorCodeBadCodeVerifier                   ErrorCode = "bad_code_verifier"
+	ErrorCodeAnonymousProviderDisabled         ErrorCode = "anonymous_provider_disabled"
+	ErrorCodeHookTimeout                       ErrorCode = "hook_timeout"
+	ErrorCodeHookTimeoutAfterRetry             ErrorCode = "hook_timeout_after_retry"
+	ErrorCodeHookPayloadOverSizeLimit          ErrorCode = "hook_payload_over_size_limit"
+	ErrorCodeHookPayloadInvalidContentType     ErrorCode = "hook_payload_invalid_content_type"
+	ErrorCodeRequestTimeout                    ErrorCode = "request_timeout"
+	ErrorCodeInvalidCredentials                ErrorCode = "invalid_credentials"
+	ErrorCodeEmailAddressNotAuthorized         ErrorCode = "email_address_not_authorized"
+	ErrorCodeEmailAddressInvalid               ErrorCode = "email_address_invalid"
+)
+
+// Sentinel errors for the error codes not covered by those in error.go. Each
+// matches an APIError with the corresponding ErrorCode through errors.Is.
+var (
+	ErrUnexpectedFailure                 = errors.New("unexpected failure")
+	ErrValidationFailed                  = errors.New("validation failed")
+	ErrBadJSON                           = errors.New("request body is not valid JSON")
+	ErrEmailExists                       = errors.New("email address already exists")
+	ErrPhoneExists                       = errors.New("phone number already exists")
+	ErrUserNotFound                      = errors.New("user not found")
+	ErrSessionExpired                    = errors.New("session expired")
+	ErrRefreshTokenAlreadyUsed           = errors.New("refresh token already used")
+	ErrFlowStateNotFound                 = errors.New("flow state not found")
+	ErrFlowStateExpired                  = errors.New("flow state expired")
+	ErrSignupDisabled                    = errors.New("signups are disabled")
+	ErrUserBanned                        = errors.New("user is banned")
+	ErrProviderEmailNeedsVerification    = errors.New("provider email needs verification")
+	ErrInviteNotFound                    = errors.New("invite not found")
+	ErrBadOAuthState                     = errors.New("invalid oauth state")
+	ErrBadOAuthCallback                  = errors.New("invalid oauth callback")
+	ErrOAuthProviderNotSupported         = errors.New("oauth provider not supported")
+	ErrUnexpectedAudience                = errors.New("unexpected audience")
+	ErrSingleIdentityNotDeletable        = errors.New("the only identity of a user cannot be deleted")
+	ErrEmailConflictIdentityNotDeletable = errors.New("identity cannot be deleted as it would cause an email conflict")
+	ErrIdentityAlreadyExists             = errors.New("identity already exists")
+	ErrEmailProviderDisabled             = errors.New("email sign in is disabled")
+	ErrPhoneProviderDisabled             = errors.New("phone sign in is disabled")
+	ErrTooManyEnrolledMFAFactors         = errors.New("too many enrolled mfa factors")
+	ErrMFAFactorNameConflict             = errors.New("mfa factor name already in use")
+	ErrMFAFactorNotFound                 = errors.New("mfa factor not found")
+	ErrMFAIPAddressMismatch              = errors.New("mfa challenge was created from a different ip address")
+	ErrMFAChallengeExpired               = errors.New("mfa challenge expired")
+	ErrMFAVerificationFailed             = errors.New("mfa verification failed")
+	ErrMFAVerificationRejected           = errors.New("mfa verification rejected")
+	ErrMFAVerifiedFactorExists           = errors.New("a verified mfa factor already exists")
+	ErrMFATOTPEnrollNotEnabled           = errors.New("totp mfa enrollment is disabled")
+	ErrMFATOTPVerifyNotEnabled           = errors.New("totp mfa verification is disabled")
+	ErrMFAPhoneEnrollNotEnabled          = errors.New("phone mfa enrollment is disabled")
+	ErrMFAPhoneVerifyNotEnabled          = errors.New("phone mfa verification is disabled")
+	ErrMFAWebAuthnEnrollNotEnabled       = errors.New("webauthn mfa enrollment is disabled")
+	ErrMFAWebAuthnVerifyNotEnabled       = errors.New("webauthn mfa verification is disabled")
+	ErrInsufficientAAL                   = errors.New("insufficient authenticator assurance level")
+	ErrCaptchaFailed                     = errors.New("captcha verification failed")
+	ErrSAMLProviderDisabled              = errors.New("saml sso is disabled")
+	ErrManualLinkingDisabled             = errors.New("manual identity linking is disabled")
+	ErrSMSSendFailed                     = errors.New("failed sending sms")
+	ErrPhoneNotConfirmed                 = errors.New("phone not confirmed")
+	ErrReauthNonceMissing                = errors.New("reauthentication nonce missing")
+	ErrSAMLRelayStateNotFound            = errors.New("saml relay state not found")
+	ErrSAMLRelayStateExpired             = errors.New("saml relay state expired")
+	ErrSAMLIdPNotFound                   = errors.New("saml identity provider not found")
+	ErrSAMLAssertionNoUserID             = errors.New("saml assertion has no user id")
+	ErrSAMLAssertionNoEmail              = errors.New("saml assertion has no email")
+	ErrSSOProviderNotFound               = errors.New("sso provider not found")
+	ErrSAMLMetadataFetchFailed           = errors.New("failed fetching saml metadata")
+	ErrSAMLIdPAlreadyExists              = errors.New("saml identity provider already exists")
+	ErrSSODomainAlreadyExists            = errors.New("sso domain already exists")
+	ErrSAMLEntityIDMismatch              = errors.New("saml entity id mismatch")
+	ErrConflict                          = errors.New("conflicting concurrent request")
+	ErrProviderDisabled                  = errors.New("provider is disabled")
+	ErrUserSSOManaged                    = errors.New("user is managed by sso")
+	ErrReauthenticationNeeded            = errors.New("reauthentication needed")
+	ErrSamePassword                      = errors.New("new password must be different from the old one")
+	ErrReauthenticationNotValid          = errors.New("reauthentication nonce is not valid")
+	ErrOTPExpired                        = errors.New("otp has expired or is invalid")
+	ErrOTPDisabled                       = errors.New("otp sign in is disabled")
+	ErrIdentityNotFound                  = errors.New("identity not found")
+	ErrWeakPassword                      = errors.New("password is too weak")
+	ErrRequestRateLimitExceeded          = errors.New("request rate limit exceeded")
+	ErrSMSSendLimitExceeded              = errors.New("sms send limit exceeded")
+	ErrBadCodeVerifier                   = errors.New("code verifier does not match")
+	ErrAnonymousProviderDisabled         = errors.New("anonymous sign in is disabled")
+	ErrHookTimeout                       = errors.New("auth hook timed out")
+	ErrHookTimeoutAfterRetry             = errors.New("auth hook timed out after retrying")
+	ErrHookPayloadOverSizeLimit          = errors.New("auth hook payload is too large")
+	ErrHookPayloadInvalidContentType     = errors.New("auth hook payload has an invalid content type")
+	ErrRequestTimeout                    = errors.New("request timed out")
+	ErrEmailAddressNotAuthorized         = errors.New("email address is not authorized")
+	ErrEmailAddressInvalid               = errors.New("email address is invalid")
+)
+
+var distinctErrors = map[ErrorCode]error{
+	ErrorCodeUnexpectedFailure:                 ErrUnexpectedFailure,
+	ErrorCodeValidationFailed:                  ErrValidationFailed,
+	ErrorCodeBadJSON:                           ErrBadJSON,
+	ErrorCodeEmailExists:                       ErrEmailExists,
+	ErrorCodePhoneExists:                       ErrPhoneExists,
+	ErrorCodeBadJWT:                            ErrInvalidJWT,
+	ErrorCodeNotAdmin:                          ErrNotAdmin,
+	ErrorCodeNoAuthorization:                   ErrNoAuthorization,
+	ErrorCodeUserNotFound:                      ErrUserNotFound,
+	ErrorCodeSessionNotFound:                   ErrSessionNotFound,
+	ErrorCodeSessionExpired:                    ErrSessionExpired,
+	ErrorCodeRefreshTokenNotFound:              ErrRefreshTokenNotFound,
+	ErrorCodeRefreshTokenAlreadyUsed:           ErrRefreshTokenAlreadyUsed,
+	ErrorCodeFlowStateNotFound:                 ErrFlowStateNotFound,
+	ErrorCodeFlowStateExpired:                  ErrFlowStateExpired,
+	ErrorCodeSignupDisabled:                    ErrSignupDisabled,
+	ErrorCodeUserBanned:                        ErrUserBanned,
+	ErrorCodeProviderEmailNeedsVerification:    ErrProviderEmailNeedsVerification,
+	ErrorCodeInviteNotFound:                    ErrInviteNotFound,
+	ErrorCodeBadOAuthState:                     ErrBadOAuthState,
+	ErrorCodeBadOAuthCallback:                  ErrBadOAuthCallback,
+	ErrorCodeOAuthProviderNotSupported:         ErrOAuthProviderNotSupported,
+	ErrorCodeUnexpectedAudience:                ErrUnexpectedAudience,
+	ErrorCodeSingleIdentityNotDeletable:        ErrSingleIdentityNotDeletable,
+	ErrorCodeEmailConflictIdentityNotDeletable: ErrEmailConflictIdentityNotDeletable,
+	ErrorCodeIdentityAlreadyExists:             ErrIdentityAlreadyExists,
+	ErrorCodeEmailProviderDisabled:             ErrEmailProviderDisabled,
+	ErrorCodePhoneProviderDisabled:             ErrPhoneProviderDisabled,
+	ErrorCodeTooManyEnrolledMFAFactors:         ErrTooManyEnrolledMFAFactors,
+	ErrorCodeMFAFactorNameConflict:             ErrMFAFactorNameConflict,
+	ErrorCodeMFAFactorNotFound:                 ErrMFAFactorNotFound,
+	ErrorCodeMFAIPAddressMismatch:              ErrMFAIPAddressMismatch,
+	ErrorCodeMFAChallengeExpired:               ErrMFAChallengeExpired,
+	ErrorCodeMFAVerificationFailed:             ErrMFAVerificationFailed,
+	ErrorCodeMFAVerificationRejected:           ErrMFAVerificationRejected,
+	ErrorCodeMFAVerifiedFactorExists:           ErrMFAVerifiedFactorExists,
+	ErrorCodeMFATOTPEnrollNotEnabled:           ErrMFATOTPEnrollNotEnabled,
+	ErrorCodeMFATOTPVerifyNotEnabled:           ErrMFATOTPVerifyNotEnabled,
+	ErrorCodeMFAPhoneEnrollNotEnabled:          ErrMFAPhoneEnrollNotEnabled,
+	ErrorCodeMFAPhoneVerifyNotEnabled:          ErrMFAPhoneVerifyNotEnabled,
+	ErrorCodeMFAWebAuthnEnrollNotEnabled:       ErrMFAWebAuthnEnrollNotEnabled,
+	ErrorCodeMFAWebAuthnVerifyNotEnabled:       ErrMFAWebAuthnVerifyNotEnabled,
+	ErrorCodeInsufficientAAL:                   ErrInsufficientAAL,
+	ErrorCodeCaptchaFailed:                     ErrCaptchaFailed,
+	ErrorCodeSAMLProviderDisabled:              ErrSAMLProviderDisabled,
+	ErrorCodeManualLinkingDisabled:             ErrManualLinkingDisabled,
+	ErrorCodeSMSSendFailed:                     ErrSMSSendFailed,
+	ErrorCodeEmailNotConfirmed:                 ErrEmailNotConfirmed,
+	ErrorCodePhoneNotConfirmed:                 ErrPhoneNotConfirmed,
+	ErrorCodeReauthNonceMissing:                ErrReauthNonceMissing,
+	ErrorCodeSAMLRelayStateNotFound:            ErrSAMLRelayStateNotFound,
+	ErrorCodeSAMLRelayStateExpired:             ErrSAMLRelayStateExpired,
+	ErrorCodeSAMLIdPNotFound:                   ErrSAMLIdPNotFound,
+	ErrorCodeSAMLAssertionNoUserID:             ErrSAMLAssertionNoUserID,
+	ErrorCodeSAMLAssertionNoEmail:              ErrSAMLAssertionNoEmail,
+	ErrorCodeUserAlreadyExists:                 ErrUserAlreadyExists,
+	ErrorCodeSSOProviderNotFound:               ErrSSOProviderNotFound,
+	ErrorCodeSAMLMetadataFetchFailed:           ErrSAMLMetadataFetchFailed,
+	ErrorCodeSAMLIdPAlreadyExists:              ErrSAMLIdPAlreadyExists,
+	ErrorCodeSSODomainAlreadyExists:            ErrSSODomainAlreadyExists,
+	ErrorCodeSAMLEntityIDMismatch:              ErrSAMLEntityIDMismatch,
+	ErrorCodeConflict:                          ErrConflict,
+	ErrorCodeProviderDisabled:                  ErrProviderDisabled,
+	ErrorCodeUserSSOManaged:                    ErrUserSSOManaged,
+	ErrorCodeReauthenticationNeeded:            ErrReauthenticationNeeded,
+	ErrorCodeSamePassword:                      ErrSamePassword,
+	ErrorCodeReauthenticationNotValid:          ErrReauthenticationNotValid,
+	ErrorCodeOTPExpired:                        ErrOTPExpired,
+	ErrorCodeOTPDisabled:                       ErrOTPDisabled,
+	ErrorCodeIdentityNotFound:                  ErrIdentityNotFound,
+	ErrorCodeWeakPassword:                      ErrWeakPassword,
+	ErrorCodeOverRequestRateLimit:              ErrRequestRateLimitExceeded,
+	ErrorCodeOverEmailSendRateLimit:            ErrEmailSendLimitExceeded,
+	ErrorCodeOverSMSSendRateLimit:              ErrSMSSendLimitExceeded,
+	ErrorCodeBadCodeVerifier:                   ErrBadCodeVerifier,
+	ErrorCodeAnonymousProviderDisabled:         ErrAnonymousProviderDisabled,
+	ErrorCodeHookTimeout:                       ErrHookTimeout,
+	ErrorCodeHookTimeoutAfterRetry:             ErrHookTimeoutAfterRetry,
+	ErrorCodeHookPayloadOverSizeLimit:          ErrHookPayloadOverSizeLimit,
+	ErrorCodeHookPayloadInvalidContentType:     ErrHookPayloadInvalidContentType,
+	ErrorCodeRequestTimeout:                    ErrRequestTimeout,
+	ErrorCodeInvalidCredentials:                ErrInvalidCredentials,
+	ErrorCodeEmailAddressNotAuthorized:         ErrEmailAddressNotAuthorized,
+	ErrorCodeEmailAddressInvalid:               ErrEmailAddressInvalid,
+}
+
+// IsRateLimited reports whether err means the Auth server is rate limiting
+// the caller, e.g. too many emails were sent, or that the client's own rate
+// limiter refused to send the request.
+func IsRateLimited(err error) bool {
+	var limitErr *RateLimitError
+	if errors.As(err, &limitErr) {
+		return true
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.ErrorCode {
+	case ErrorCodeOverRequestRateLimit, ErrorCodeOverEmailSendRateLimit, ErrorCodeOverSMSSendRateLimit:
+		return true
+	}
+	return apiErr.StatusCode == http.StatusTooManyRequests
+}
+
+// IsRetryable reports whether the request that returned err may succeed if
+// it is sent again later, e.g. after a network error, a timeout, rate
+// limiting or the Auth server being temporarily unavailable.
+func IsRetryable(err error) bool {
+	var dispatchErr *RequestDispatchError
+	if errors.As(err, &dispatchErr) {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	if IsRateLimited(err) {
+		return true
+	}
+	switch apiErr.ErrorCode {
+	case ErrorCodeRequestTimeout, ErrorCodeHookTimeout, ErrorCodeHookTimeoutAfterRetry, ErrorCodeConflict:
+		return true
+	}
+	switch apiErr.StatusCode {
+	case http.StatusRequestTimeout, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// IsClientError reports whether err means the Auth server rejected the
+// request because of the request itself, e.g. invalid credentials or a weak
+// password, so that sending it again will not help. Its ErrorCode says what
+// to tell the user.
+func IsClientError(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.StatusCode >= 400 && apiErr.StatusCode < 500 && !IsRetryable(err)
+}
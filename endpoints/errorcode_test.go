@@ -0,0 +1,75 @@
+package endpoints_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mrehanabbasi/supabase-auth-go/endpoints"
+)
+
+func TestErrorCodes(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		status      int
+		code        endpoints.ErrorCode
+		sentinel    error
+		rateLimited bool
+		retryable   bool
+		clientError bool
+	}{
+		{http.StatusForbidden, endpoints.ErrorCodeOTPExpired, endpoints.ErrOTPExpired, false, false, true},
+		{http.StatusUnprocessableEntity, endpoints.ErrorCodeEmailExists, endpoints.ErrEmailExists, false, false, true},
+		{http.StatusUnprocessableEntity, endpoints.ErrorCodePhoneExists, endpoints.ErrPhoneExists, false, false, true},
+		{http.StatusUnprocessableEntity, endpoints.ErrorCodeMFAVerificationFailed, endpoints.ErrMFAVerificationFailed, false, false, true},
+		{http.StatusNotFound, endpoints.ErrorCodeSSOProviderNotFound, endpoints.ErrSSOProviderNotFound, false, false, true},
+		{http.StatusUnprocessableEntity, endpoints.ErrorCodeIdentityAlreadyExists, endpoints.ErrIdentityAlreadyExists, false, false, true},
+		{http.StatusForbidden, endpoints.ErrorCodeUserBanned, endpoints.ErrUserBanned, false, false, true},
+		{http.StatusUnauthorized, endpoints.ErrorCodeReauthenticationNeeded, endpoints.ErrReauthenticationNeeded, false, false, true},
+		{http.StatusBadRequest, endpoints.ErrorCodeInvalidCredentials, endpoints.ErrInvalidCredentials, false, false, true},
+		{http.StatusTooManyRequests, endpoints.ErrorCodeOverRequestRateLimit, endpoints.ErrRequestRateLimitExceeded, true, true, false},
+		{http.StatusTooManyRequests, endpoints.ErrorCodeOverEmailSendRateLimit, endpoints.ErrEmailSendLimitExceeded, true, true, false},
+		{http.StatusConflict, endpoints.ErrorCodeConflict, endpoints.ErrConflict, false, true, false},
+		{http.StatusGatewayTimeout, endpoints.ErrorCodeHookTimeout, endpoints.ErrHookTimeout, false, true, false},
+		{http.StatusServiceUnavailable, "", nil, false, true, false},
+		{http.StatusInternalServerError, endpoints.ErrorCodeUnexpectedFailure, endpoints.ErrUnexpectedFailure, false, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d %s", tt.status, tt.code), func(t *testing.T) {
+			assert := assert.New(t)
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = fmt.Fprintf(w, `{"code":%d,"error_code":%q,"msg":"message"}`, tt.status, tt.code)
+			}))
+			defer srv.Close()
+
+			_, err := endpoints.New("", "api_key").WithCustomAuthURL(srv.URL).GetSettings(ctx)
+			assert.Error(err)
+			if tt.sentinel != nil {
+				assert.ErrorIs(err, tt.sentinel)
+			}
+			assert.Equal(tt.rateLimited, endpoints.IsRateLimited(err), "IsRateLimited")
+			assert.Equal(tt.retryable, endpoints.IsRetryable(err), "IsRetryable")
+			assert.Equal(tt.clientError, endpoints.IsClientError(err), "IsClientError")
+		})
+	}
+
+	// Network errors are retryable, unless the context was cancelled.
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+	client := endpoints.New("", "api_key").WithCustomAuthURL(srv.URL)
+	_, err := client.GetSettings(ctx)
+	assert.True(t, endpoints.IsRetryable(err))
+	assert.False(t, endpoints.IsClientError(err))
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = client.GetSettings(cancelled)
+	assert.False(t, endpoints.IsRetryable(err))
+}
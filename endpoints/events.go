@@ -0,0 +1,179 @@
+package endpoints
+
+import (
+	"sync"
+
+	"github.com/mrehanabbasi/supabase-auth-go/types"
+)
+
+// eventBus delivers auth state changes to subscribers. It is shared by all
+// copies of a client, so subscribing on one copy receives events caused by
+// requests made with any other copy.
+type eventBus struct {
+	mu          sync.Mutex
+	nextID      int
+	subscribers map[int]*subscriber
+}
+
+// maxQueuedEvents is how many events may wait for a subscriber before the
+// oldest are dropped.
+const maxQueuedEvents = 100
+
+// subscriber queues events and delivers them from its own goroutine, so a
+// slow callback only delays its own events.
+type subscriber struct {
+	fn func(types.AuthStateChange)
+
+	mu    sync.Mutex
+	queue []types.AuthStateChange
+	// dropped counts the events dropped since the last one delivered.
+	dropped int
+	notify  chan struct{}
+	done    chan struct{}
+}
+
+func newEventBus() *eventBus {
+	return &eventBus{
+		subscribers: make(map[int]*subscriber),
+	}
+}
+
+func (b *eventBus) subscribe(fn func(types.AuthStateChange)) func() {
+	s := &subscriber{
+		fn:     fn,
+		notify: make(chan struct{}, 1),
+		done:   make(chan struct{}),
+	}
+	go s.run()
+
+	b.mu.Lock()
+	id := b.nextID
+	b.nextID++
+	b.subscribers[id] = s
+	b.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscribers, id)
+			b.mu.Unlock()
+			close(s.done)
+		})
+	}
+}
+
+// active reports whether anyone is subscribed, so that events that take work
+// to build can be skipped.
+func (b *eventBus) active() bool {
+	if b == nil {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subscribers) > 0
+}
+
+func (b *eventBus) publish(change types.AuthStateChange) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, s := range b.subscribers {
+		s.enqueue(change)
+	}
+}
+
+func (s *subscriber) enqueue(change types.AuthStateChange) {
+	s.mu.Lock()
+	if len(s.queue) >= maxQueuedEvents {
+		s.queue = s.queue[1:]
+		s.dropped++
+	}
+	s.queue = append(s.queue, change)
+	s.mu.Unlock()
+
+	select {
+	case s.notify <- struct{}{}:
+	default:
+		// A notification is already pending.
+	}
+}
+
+func (s *subscriber) run() {
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-s.notify:
+		}
+
+		s.mu.Lock()
+		queue := s.queue
+		s.queue = nil
+		if len(queue) > 0 {
+			queue[0].Dropped = s.dropped
+			s.dropped = 0
+		}
+		s.mu.Unlock()
+
+		for _, change := range queue {
+			select {
+			case <-s.done:
+				return
+			default:
+			}
+			s.fn(change)
+		}
+	}
+}
+
+// OnAuthStateChange registers fn to be called whenever a request made with
+// this client, or any copy of it, changes the session state. It returns a
+// function that unsubscribes fn.
+//
+// Each subscriber is called from its own goroutine, in the order the events
+// occurred. A slow subscriber does not block requests or other subscribers.
+// If more than 100 events are waiting for it, the oldest are dropped, and
+// the Dropped field of the next event delivered says how many.
+func (c *Client) OnAuthStateChange(fn func(types.AuthStateChange)) (unsubscribe func()) {
+	if c.events == nil {
+		// Client was not created with New; events can never be published.
+		return func() {}
+	}
+	return c.events.subscribe(fn)
+}
+
+// emit publishes an event carrying the given session.
+func (c *Client) emit(event types.AuthChangeEvent, session *types.Session) {
+	if c.events == nil {
+		return
+	}
+	change := types.AuthStateChange{Event: event}
+	if session != nil {
+		s := *session
+		change.Session = &s
+		change.User = &s.User
+	}
+	c.events.publish(change)
+}
+
+// emitUser publishes an event carrying only the user.
+func (c *Client) emitUser(event types.AuthChangeEvent, user types.User) {
+	if c.events == nil {
+		return
+	}
+	c.events.publish(types.AuthStateChange{
+		Event: event,
+		User:  &user,
+	})
+}
+
+// emitVerified publishes the event for a successful email or phone
+// verification. Recovery links sign the user in to reset their password.
+func (c *Client) emitVerified(verificationType types.VerificationType, session *types.Session) {
+	if verificationType == types.VerificationTypeRecovery {
+		c.emit(types.AuthChangeEventPasswordRecovery, session)
+		return
+	}
+	c.emit(types.AuthChangeEventSignedIn, session)
+}
@@ -0,0 +1,181 @@
+package endpoints_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrehanabbasi/supabase-auth-go/endpoints"
+	"github.com/mrehanabbasi/supabase-auth-go/types"
+)
+
+func TestOnAuthStateChange(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	ctx := context.Background()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			_ = json.NewEncoder(w).Encode(types.Session{
+				AccessToken:  "access",
+				RefreshToken: "refresh",
+				User:         types.User{Email: "user@test.com"},
+			})
+		case "/logout":
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer srv.Close()
+
+	client := endpoints.New("", "api_key").WithCustomAuthURL(srv.URL)
+
+	// A subscriber that never returns must not block the others.
+	blockSlow := make(chan struct{})
+	defer close(blockSlow)
+	unsubscribeSlow := client.OnAuthStateChange(func(types.AuthStateChange) {
+		<-blockSlow
+	})
+	defer unsubscribeSlow()
+
+	events := make(chan types.AuthStateChange, 10)
+	unsubscribe := client.OnAuthStateChange(func(c types.AuthStateChange) {
+		events <- c
+	})
+
+	_, err := client.SignInWithEmailPassword(ctx, "user@test.com", "password")
+	require.NoError(err)
+	_, err = client.RefreshToken(ctx, "refresh")
+	require.NoError(err)
+
+	// Copies of the client share subscribers.
+	require.NoError(client.WithToken("access").Logout(ctx))
+
+	want := []types.AuthChangeEvent{
+		types.AuthChangeEventSignedIn,
+		types.AuthChangeEventTokenRefreshed,
+		types.AuthChangeEventSignedOut,
+	}
+	for _, event := range want {
+		select {
+		case c := <-events:
+			assert.Equal(event, c.Event)
+			if event == types.AuthChangeEventSignedOut {
+				assert.Nil(c.Session)
+			} else {
+				require.NotNil(c.Session)
+				assert.Equal("access", c.Session.AccessToken)
+				assert.Equal("user@test.com", c.User.Email)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for %s", event)
+		}
+	}
+
+	unsubscribe()
+	_, err = client.RefreshToken(ctx, "refresh")
+	require.NoError(err)
+	select {
+	case c := <-events:
+		t.Fatalf("received %s after unsubscribing", c.Event)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestOnAuthStateChangeVerify(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	ctx := context.Background()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/verify":
+			w.Header().Set("Location", "https://example.com/callback#access_token=access&refresh_token=refresh&token_type=bearer&expires_in=3600")
+			w.WriteHeader(http.StatusSeeOther)
+		case "/user":
+			assert.Equal("Bearer access", r.Header.Get("Authorization"))
+			_ = json.NewEncoder(w).Encode(types.UserResponse{User: types.User{Email: "user@test.com"}})
+		}
+	}))
+	defer srv.Close()
+
+	client := endpoints.New("", "api_key").WithCustomAuthURL(srv.URL)
+	events := make(chan types.AuthStateChange, 1)
+	defer client.OnAuthStateChange(func(c types.AuthStateChange) {
+		events <- c
+	})()
+
+	_, err := client.Verify(ctx, types.VerifyRequest{
+		Type:       types.VerificationTypeRecovery,
+		Token:      "token",
+		RedirectTo: "https://example.com/callback",
+	})
+	require.NoError(err)
+
+	select {
+	case c := <-events:
+		assert.Equal(types.AuthChangeEventPasswordRecovery, c.Event)
+		require.NotNil(c.Session)
+		assert.Equal("access", c.Session.AccessToken)
+		assert.Equal("user@test.com", c.Session.User.Email)
+		assert.Equal("user@test.com", c.User.Email)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for PASSWORD_RECOVERY")
+	}
+}
+
+func TestOnAuthStateChangeSlowSubscriber(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	ctx := context.Background()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(types.Session{AccessToken: "access", RefreshToken: "refresh"})
+	}))
+	defer srv.Close()
+
+	client := endpoints.New("", "api_key").WithCustomAuthURL(srv.URL)
+
+	unblock := make(chan struct{})
+	var received []types.AuthStateChange
+	done := make(chan struct{})
+	const total = 300
+	defer client.OnAuthStateChange(func(c types.AuthStateChange) {
+		<-unblock
+		received = append(received, c)
+		n := len(received)
+		for _, r := range received {
+			n += r.Dropped
+		}
+		if n == total {
+			close(done)
+		}
+	})()
+
+	for range total {
+		_, err := client.RefreshToken(ctx, "refresh")
+		require.NoError(err)
+	}
+	close(unblock)
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for events")
+	}
+	// Every event is either delivered or counted as dropped, and at most
+	// 100 were kept while the subscriber was blocked, besides the one it was
+	// blocked on.
+	assert.LessOrEqual(len(received), 101)
+	dropped := 0
+	for _, r := range received {
+		dropped += r.Dropped
+	}
+	assert.Equal(total-len(received), dropped)
+}
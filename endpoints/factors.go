@@ -5,7 +5,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"time"
 
@@ -26,31 +25,27 @@ func (c *Client) EnrollFactor(ctx context.Context, req types.EnrollFactorRequest
 
 	body, err := json.Marshal(req)
 	if err != nil {
-		return nil, err
+		return nil, newRequestEncodingError(err)
 	}
 
-	r, err := c.newRequest(ctx, factorsPath, http.MethodPost, bytes.NewBuffer(body))
+	r, err := c.newRequest(ctx, "EnrollFactor", factorsPath, http.MethodPost, bytes.NewBuffer(body))
 	if err != nil {
-		return nil, err
+		return nil, newRequestCreationError(err)
 	}
 
-	resp, err := c.client.Do(r)
+	resp, err := c.do(r)
 	if err != nil {
-		return nil, err
+		return nil, newRequestDispatchError(err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		fullBody, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return nil, fmt.Errorf("response status code %d", resp.StatusCode)
-		}
-		return nil, fmt.Errorf("response status code %d: %s", resp.StatusCode, fullBody)
+		return nil, handleErrorResponse(resp)
 	}
 
 	var res types.EnrollFactorResponse
 	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
-		return nil, err
+		return nil, newResponseDecodingError(err)
 	}
 	return &res, nil
 }
@@ -60,23 +55,19 @@ func (c *Client) EnrollFactor(ctx context.Context, req types.EnrollFactorRequest
 // Challenge a factor.
 func (c *Client) ChallengeFactor(ctx context.Context, req types.ChallengeFactorRequest) (*types.ChallengeFactorResponse, error) {
 	url := fmt.Sprintf("%s/%s/challenge", factorsPath, req.FactorID)
-	r, err := c.newRequest(ctx, url, http.MethodPost, nil)
+	r, err := c.newRequest(ctx, "ChallengeFactor", url, http.MethodPost, nil)
 	if err != nil {
-		return nil, err
+		return nil, newRequestCreationError(err)
 	}
 
-	resp, err := c.client.Do(r)
+	resp, err := c.do(r)
 	if err != nil {
-		return nil, err
+		return nil, newRequestDispatchError(err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		fullBody, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return nil, fmt.Errorf("response status code %d", resp.StatusCode)
-		}
-		return nil, fmt.Errorf("response status code %d: %s", resp.StatusCode, fullBody)
+		return nil, handleErrorResponse(resp)
 	}
 
 	type decodeResp struct {
@@ -85,7 +76,7 @@ func (c *Client) ChallengeFactor(ctx context.Context, req types.ChallengeFactorR
 	}
 	res := decodeResp{}
 	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
-		return nil, err
+		return nil, newResponseDecodingError(err)
 	}
 	expiresAt := time.Unix(res.Expiry, 0)
 	return &types.ChallengeFactorResponse{
@@ -105,12 +96,12 @@ func (c *Client) VerifyFactor(ctx context.Context, req types.VerifyFactorRequest
 		return nil, newRequestEncodingError(err)
 	}
 
-	r, err := c.newRequest(ctx, url, http.MethodPost, body)
+	r, err := c.newRequest(ctx, "VerifyFactor", url, http.MethodPost, body)
 	if err != nil {
 		return nil, newRequestCreationError(err)
 	}
 
-	resp, err := c.client.Do(r)
+	resp, err := c.do(r)
 	if err != nil {
 		return nil, newRequestDispatchError(err)
 	}
@@ -124,6 +115,12 @@ func (c *Client) VerifyFactor(ctx context.Context, req types.VerifyFactorRequest
 	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
 		return nil, newResponseDecodingError(err)
 	}
+
+	c.emit(types.AuthChangeEventMFAChallengeVerified, &res.Session)
+
+	if err := c.saveSession(ctx, res.Session); err != nil {
+		return &res, err
+	}
 	return &res, nil
 }
 
@@ -133,12 +130,12 @@ func (c *Client) VerifyFactor(ctx context.Context, req types.VerifyFactorRequest
 func (c *Client) UnenrollFactor(ctx context.Context, req types.UnenrollFactorRequest) (*types.UnenrollFactorResponse, error) {
 	url := fmt.Sprintf("%s/%s", factorsPath, req.FactorID)
 
-	r, err := c.newRequest(ctx, url, http.MethodDelete, nil)
+	r, err := c.newRequest(ctx, "UnenrollFactor", url, http.MethodDelete, nil)
 	if err != nil {
 		return nil, newRequestCreationError(err)
 	}
 
-	resp, err := c.client.Do(r)
+	resp, err := c.do(r)
 	if err != nil {
 		return nil, newRequestDispatchError(err)
 	}
@@ -14,12 +14,12 @@ var healthPath = "/health"
 //
 // Check the health of the Auth server.
 func (c *Client) HealthCheck(ctx context.Context) (*types.HealthCheckResponse, error) {
-	r, err := c.newRequest(ctx, healthPath, http.MethodGet, nil)
+	r, err := c.newRequest(ctx, "HealthCheck", healthPath, http.MethodGet, nil)
 	if err != nil {
 		return nil, newRequestCreationError(err)
 	}
 
-	resp, err := c.client.Do(r)
+	resp, err := c.do(r)
 	if err != nil {
 		return nil, newRequestDispatchError(err)
 	}
@@ -0,0 +1,148 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/mrehanabbasi/supabase-auth-go/types"
+)
+
+const (
+	identitiesPath          = "/user/identities"
+	identitiesAuthorizePath = "/user/identities/authorize"
+)
+
+// GET /user/identities/authorize
+//
+// Link an identity from an external oauth provider to the logged in user
+// (requires authentication). Manual linking must be enabled on the Auth
+// server.
+//
+// Scopes are optional additional scopes depending on the provider (email and
+// name are requested by default).
+//
+// Like Authorize, this method returns the URL the user should be redirected
+// to. Once the user signs in with the provider, the identity is added to
+// their account.
+func (c *Client) LinkIdentity(ctx context.Context, req types.LinkIdentityRequest) (*types.LinkIdentityResponse, error) {
+	r, err := c.newRequest(ctx, "LinkIdentity", identitiesAuthorizePath, http.MethodGet, nil)
+	if err != nil {
+		return nil, newRequestCreationError(err)
+	}
+
+	q := r.URL.Query()
+	q.Add("scopes", req.Scopes)
+	q.Add("provider", string(req.Provider))
+	// Return the URL in the response body instead of redirecting, since the
+	// request must carry the user's access token.
+	q.Add("skip_http_redirect", "true")
+
+	verifier, err := addPKCEChallenge(q, req.FlowType)
+	if err != nil {
+		return nil, err
+	}
+
+	state, redirectTo, err := c.storeVerifier(ctx, verifier, req.RedirectTo)
+	if err != nil {
+		return nil, err
+	}
+	q.Add("redirect_to", redirectTo)
+
+	r.URL.RawQuery = q.Encode()
+
+	resp, err := c.do(r)
+	if err != nil {
+		return nil, newRequestDispatchError(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, handleErrorResponse(resp)
+	}
+
+	var res struct {
+		URL string `json:"url"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return nil, newResponseDecodingError(err)
+	}
+	if res.URL == "" {
+		return nil, ErrRedirectURLNotInResponse
+	}
+
+	return &types.LinkIdentityResponse{
+		AuthorizationURL: res.URL,
+		Verifier:         verifier,
+		State:            state,
+	}, nil
+}
+
+// DELETE /user/identities/{identity_id}
+//
+// Unlink an identity from the logged in user (requires authentication).
+// Manual linking must be enabled on the Auth server.
+//
+// The user's identities are fetched first, and the request is refused with
+// an APIError matching ErrIdentityNotFound if the identity is not the user's,
+// or ErrSingleIdentityNotDeletable if it would leave the user without a way
+// to sign in, as the Auth server would respond.
+func (c *Client) UnlinkIdentity(ctx context.Context, req types.UnlinkIdentityRequest) error {
+	if req.IdentityID == uuid.Nil {
+		return types.ErrInvalidUnlinkIdentityRequest
+	}
+
+	identities, err := c.GetUserIdentities(ctx)
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, identity := range identities.Identities {
+		if identity.IdentityID == req.IdentityID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return newLocalAPIError(http.StatusNotFound, ErrorCodeIdentityNotFound, "Identity doesn't exist")
+	}
+	if len(identities.Identities) <= 1 {
+		return newLocalAPIError(http.StatusUnprocessableEntity, ErrorCodeSingleIdentityNotDeletable, "User must have at least 1 identity after unlinking")
+	}
+
+	path := fmt.Sprintf("%s/%s", identitiesPath, req.IdentityID)
+	r, err := c.newRequest(ctx, "UnlinkIdentity", path, http.MethodDelete, nil)
+	if err != nil {
+		return newRequestCreationError(err)
+	}
+
+	resp, err := c.do(r)
+	if err != nil {
+		return newRequestDispatchError(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return handleErrorResponse(resp)
+	}
+
+	return nil
+}
+
+// Get the identities linked to the logged in user
+//
+// This is a convenience method that calls GetUser and returns the user's
+// identities (requires authentication).
+func (c *Client) GetUserIdentities(ctx context.Context) (*types.GetUserIdentitiesResponse, error) {
+	user, err := c.GetUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.GetUserIdentitiesResponse{
+		Identities: user.Identities,
+	}, nil
+}
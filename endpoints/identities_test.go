@@ -0,0 +1,88 @@
+package endpoints_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrehanabbasi/supabase-auth-go/endpoints"
+	"github.com/mrehanabbasi/supabase-auth-go/types"
+)
+
+func TestLinkIdentity(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	ctx := context.Background()
+
+	var challenge string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		challenge = r.URL.Query().Get("code_challenge")
+		if r.URL.Query().Get("provider") == string(types.ProviderGitHub) {
+			_, _ = w.Write([]byte(`{"url":"https://github.com/login/oauth/authorize"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	client := endpoints.New("", "api_key").WithCustomAuthURL(srv.URL).WithToken("token")
+
+	resp, err := client.LinkIdentity(ctx, types.LinkIdentityRequest{
+		Provider: types.ProviderGitHub,
+		FlowType: types.FlowPKCE,
+	})
+	require.NoError(err)
+	assert.Equal("https://github.com/login/oauth/authorize", resp.AuthorizationURL)
+	assert.NotEmpty(resp.Verifier)
+	assert.NotEmpty(challenge)
+
+	resp, err = client.LinkIdentity(ctx, types.LinkIdentityRequest{Provider: types.ProviderGitHub})
+	require.NoError(err)
+	assert.Empty(resp.Verifier)
+	assert.Empty(challenge)
+
+	_, err = client.LinkIdentity(ctx, types.LinkIdentityRequest{Provider: types.ProviderGoogle})
+	assert.ErrorIs(err, endpoints.ErrRedirectURLNotInResponse)
+}
+
+func TestUnlinkIdentity(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	ctx := context.Background()
+
+	identityID := uuid.New()
+	var deletes int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			deletes++
+			return
+		}
+		_ = json.NewEncoder(w).Encode(types.User{
+			Identities: []types.Identity{{IdentityID: identityID}},
+		})
+	}))
+	defer srv.Close()
+
+	client := endpoints.New("", "api_key").WithCustomAuthURL(srv.URL).WithToken("token")
+
+	// Requests the Auth server would refuse fail as if it had.
+	err := client.UnlinkIdentity(ctx, types.UnlinkIdentityRequest{IdentityID: uuid.New()})
+	assert.ErrorIs(err, endpoints.ErrIdentityNotFound)
+	var apiErr *endpoints.APIError
+	require.ErrorAs(err, &apiErr)
+	assert.Equal(http.StatusNotFound, apiErr.StatusCode)
+	assert.Equal(endpoints.ErrorCodeIdentityNotFound, apiErr.ErrorCode)
+
+	err = client.UnlinkIdentity(ctx, types.UnlinkIdentityRequest{IdentityID: identityID})
+	assert.ErrorIs(err, endpoints.ErrSingleIdentityNotDeletable)
+	require.ErrorAs(err, &apiErr)
+	assert.Equal(endpoints.ErrorCodeSingleIdentityNotDeletable, apiErr.ErrorCode)
+
+	assert.Zero(deletes)
+}
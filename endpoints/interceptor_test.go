@@ -0,0 +1,105 @@
+package endpoints_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrehanabbasi/supabase-auth-go/endpoints"
+	"github.com/mrehanabbasi/supabase-auth-go/types"
+)
+
+func TestInterceptors(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	ctx := context.Background()
+
+	var requestIDs []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestIDs = append(requestIDs, r.Header.Get("X-Request-Id"))
+		_, _ = w.Write([]byte(`{"name":"GoTrue","version":"v2"}`))
+	}))
+	defer srv.Close()
+
+	var calls []string
+	record := func(name string) types.Interceptor {
+		return func(endpoint string, req *http.Request, next types.RoundTrip) (*http.Response, error) {
+			calls = append(calls, name+" "+endpoint+" "+req.Method)
+			resp, err := next(req)
+			calls = append(calls, name+" done")
+			return resp, err
+		}
+	}
+	requestID := func(endpoint string, req *http.Request, next types.RoundTrip) (*http.Response, error) {
+		req.Header.Set("X-Request-Id", "req-1")
+		return next(req)
+	}
+
+	client := endpoints.New("", "api_key").WithCustomAuthURL(srv.URL)
+	intercepted := client.WithInterceptors(record("outer"), requestID).WithInterceptors(record("inner"))
+
+	_, err := intercepted.HealthCheck(ctx)
+	require.NoError(err)
+	assert.Equal([]string{
+		"outer HealthCheck GET",
+		"inner HealthCheck GET",
+		"inner done",
+		"outer done",
+	}, calls)
+	assert.Equal([]string{"req-1"}, requestIDs)
+
+	// The client the interceptors were added to is unchanged.
+	calls = nil
+	_, err = client.HealthCheck(ctx)
+	require.NoError(err)
+	assert.Empty(calls)
+	assert.Equal([]string{"req-1", ""}, requestIDs)
+
+	// Interceptors can short-circuit the request.
+	canned := client.WithInterceptors(func(endpoint string, req *http.Request, next types.RoundTrip) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(`{"name":"canned"}`)),
+			Request:    req,
+		}, nil
+	})
+	res, err := canned.HealthCheck(ctx)
+	require.NoError(err)
+	assert.Equal("canned", res.Name)
+	assert.Len(requestIDs, 2)
+
+	// An interceptor must return a response or an error.
+	broken := client.WithInterceptors(func(string, *http.Request, types.RoundTrip) (*http.Response, error) {
+		return nil, nil
+	})
+	_, err = broken.HealthCheck(ctx)
+	assert.Error(err)
+}
+
+func TestInterceptorsEndpointName(t *testing.T) {
+	ctx := context.Background()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	var names []string
+	client := endpoints.New("", "api_key").WithCustomAuthURL(srv.URL).WithToken("token").WithInterceptors(
+		func(endpoint string, req *http.Request, next types.RoundTrip) (*http.Response, error) {
+			names = append(names, endpoint)
+			return next(req)
+		},
+	)
+
+	require.NoError(t, client.Logout(ctx))
+	require.NoError(t, client.LogoutWithScope(ctx, types.LogoutRequest{Scope: types.LogoutScopeLocal}))
+	assert.Equal(t, []string{"Logout", "LogoutWithScope"}, names)
+}
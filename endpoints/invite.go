@@ -21,12 +21,13 @@ func (c *Client) Invite(ctx context.Context, req types.InviteRequest) (*types.In
 		return nil, newRequestEncodingError(err)
 	}
 
-	r, err := c.newRequest(ctx, invitePath, http.MethodPost, body)
+	r, err := c.newRequest(ctx, "Invite", invitePath, http.MethodPost, body)
 	if err != nil {
 		return nil, newRequestCreationError(err)
 	}
+	r = withRecipient(r, req.Email)
 
-	resp, err := c.client.Do(r)
+	resp, err := c.do(r)
 	if err != nil {
 		return nil, newRequestDispatchError(err)
 	}
@@ -0,0 +1,39 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/mrehanabbasi/supabase-auth-go/types"
+)
+
+const jwksPath = "/.well-known/jwks.json"
+
+// GET /.well-known/jwks.json
+//
+// Get the JSON Web Key Set containing the public keys used to sign access
+// tokens with asymmetric algorithms such as RS256 and ES256.
+func (c *Client) GetJWKS(ctx context.Context) (*types.JWKSResponse, error) {
+	r, err := c.newRequest(ctx, "GetJWKS", jwksPath, http.MethodGet, nil)
+	if err != nil {
+		return nil, newRequestCreationError(err)
+	}
+
+	resp, err := c.do(r)
+	if err != nil {
+		return nil, newRequestDispatchError(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, handleErrorResponse(resp)
+	}
+
+	var res types.JWKSResponse
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return nil, newResponseDecodingError(err)
+	}
+
+	return &res, nil
+}
@@ -0,0 +1,207 @@
+package endpoints
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	redacted = "[REDACTED]"
+
+	// maxLoggedBody is the most of a response body that is read to find the
+	// error code, or logged at debug level.
+	maxLoggedBody = 64 << 10
+)
+
+// sensitiveFields are the JSON fields, query parameters and headers that are
+// never logged. Names are compared case-insensitively.
+var sensitiveFields = map[string]bool{
+	"apikey":                 true,
+	"authorization":          true,
+	"cookie":                 true,
+	"password":               true,
+	"access_token":           true,
+	"refresh_token":          true,
+	"provider_token":         true,
+	"provider_refresh_token": true,
+	"id_token":               true,
+	"token":                  true,
+	"token_hash":             true,
+	"hashed_token":           true,
+	"nonce":                  true,
+	"code":                   true,
+	"auth_code":              true,
+	"code_verifier":          true,
+	"captcha_token":          true,
+	"email_otp":              true,
+	"action_link":            true,
+	"secret":                 true,
+	"qr_code":                true,
+	"uri":                    true,
+}
+
+// logCall sends r with send, and logs the outcome to the client's logger.
+// At debug level, the redacted request and response bodies are logged too.
+func (c *Client) logCall(endpoint string, r *http.Request, send func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	ctx := r.Context()
+	debug := c.logger.Enabled(ctx, slog.LevelDebug)
+
+	var reqBody []byte
+	if debug && r.GetBody != nil {
+		if body, err := r.GetBody(); err == nil {
+			reqBody, _ = io.ReadAll(io.LimitReader(body, maxLoggedBody))
+			body.Close()
+		}
+	}
+
+	start := time.Now()
+	resp, err := send(r)
+	duration := time.Since(start)
+
+	attrs := []slog.Attr{
+		slog.String("endpoint", endpoint),
+		slog.String("method", r.Method),
+		slog.String("url", redactURL(r.URL)),
+		slog.Duration("duration", duration),
+	}
+
+	if err != nil {
+		// Errors from net/http quote the URL, which may carry a token.
+		msg := strings.ReplaceAll(err.Error(), r.URL.String(), redactURL(r.URL))
+		attrs = append(attrs, slog.String("request_id", r.Header.Get("X-Request-Id")), slog.String("error", msg))
+		c.logger.LogAttrs(ctx, slog.LevelError, "auth request failed", attrs...)
+		return resp, err
+	}
+
+	id := requestID(resp)
+	if id == "" {
+		id = r.Header.Get("X-Request-Id")
+	}
+	attrs = append(attrs, slog.Int("status", resp.StatusCode), slog.String("request_id", id))
+
+	failed := resp.StatusCode >= 400
+	var respBody []byte
+	if failed || debug {
+		respBody = peekBody(resp)
+	}
+	if failed {
+		attrs = append(attrs, slog.String("error_code", errorCode(respBody)))
+		c.logger.LogAttrs(ctx, slog.LevelWarn, "auth request returned an error", attrs...)
+	} else {
+		c.logger.LogAttrs(ctx, slog.LevelInfo, "auth request", attrs...)
+	}
+
+	if debug {
+		c.logger.LogAttrs(ctx, slog.LevelDebug, "auth request bodies",
+			slog.String("endpoint", endpoint),
+			slog.Any("request_headers", redactHeader(r.Header)),
+			slog.String("request_body", redactBody(reqBody)),
+			slog.String("response_body", redactBody(respBody)),
+		)
+	}
+
+	return resp, nil
+}
+
+// peekBody reads up to maxLoggedBody bytes of the response body, and puts
+// them back so that the body can still be decoded.
+func peekBody(resp *http.Response) []byte {
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxLoggedBody))
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(data), resp.Body), resp.Body}
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// errorCode returns the error_code of an error response body, or the OAuth
+// error if there is none.
+func errorCode(body []byte) string {
+	var res struct {
+		ErrorCode string `json:"error_code"`
+		Error     string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &res); err != nil {
+		return ""
+	}
+	if res.ErrorCode != "" {
+		return res.ErrorCode
+	}
+	return res.Error
+}
+
+func redactURL(u *url.URL) string {
+	if u.RawQuery == "" {
+		return u.Redacted()
+	}
+	copied := *u
+	q := copied.Query()
+	for key := range q {
+		if sensitiveFields[strings.ToLower(key)] {
+			q.Set(key, redacted)
+		}
+	}
+	copied.RawQuery = q.Encode()
+	return copied.Redacted()
+}
+
+func redactHeader(h http.Header) map[string]string {
+	out := make(map[string]string, len(h))
+	for key, values := range h {
+		if sensitiveFields[strings.ToLower(key)] {
+			out[key] = redacted
+			continue
+		}
+		out[key] = strings.Join(values, ", ")
+	}
+	return out
+}
+
+// redactBody returns a JSON body with the values of sensitive fields replaced,
+// at any depth. Bodies that are not JSON are not logged.
+func redactBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	d := json.NewDecoder(bytes.NewReader(body))
+	d.UseNumber()
+	var v any
+	if err := d.Decode(&v); err != nil {
+		return fmt.Sprintf("[%d bytes not logged]", len(body))
+	}
+	data, err := json.Marshal(redactValue(v))
+	if err != nil {
+		return fmt.Sprintf("[%d bytes not logged]", len(body))
+	}
+	return string(data)
+}
+
+func redactValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			// Numbers are kept, as "code" is also the HTTP status of an error
+			// response.
+			if _, isNumber := value.(json.Number); sensitiveFields[strings.ToLower(key)] && !isNumber {
+				v[key] = redacted
+			} else {
+				v[key] = redactValue(value)
+			}
+		}
+	case []any:
+		for i, value := range v {
+			v[i] = redactValue(value)
+		}
+	}
+	return v
+}
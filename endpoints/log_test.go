@@ -0,0 +1,119 @@
+package endpoints_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrehanabbasi/supabase-auth-go/endpoints"
+	"github.com/mrehanabbasi/supabase-auth-go/types"
+)
+
+func TestLogger(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	ctx := context.Background()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "req-1")
+		switch r.URL.Path {
+		case "/token":
+			_, _ = w.Write([]byte(`{"access_token":"secret-access","refresh_token":"secret-refresh","user":{"email":"user@test.com"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"code":404,"error_code":"user_not_found","msg":"User not found"}`))
+		}
+	}))
+	defer srv.Close()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	client := endpoints.New("", "secret-api-key").
+		WithCustomAuthURL(srv.URL).
+		WithToken("secret-bearer").
+		WithLogger(logger)
+
+	_, err := client.Token(ctx, types.TokenRequest{
+		GrantType: "password",
+		Email:     "user@test.com",
+		Password:  "secret-password",
+		SecurityEmbed: types.SecurityEmbed{
+			Security: types.GoTrueMetaSecurity{CaptchaToken: "secret-captcha"},
+		},
+	})
+	require.NoError(err)
+
+	_, err = client.AdminGetUser(ctx, types.AdminGetUserRequest{UserID: uuid.New()})
+	require.Error(err)
+
+	logs := buf.String()
+	for _, secret := range []string{"secret-api-key", "secret-bearer", "secret-password", "secret-captcha", "secret-access", "secret-refresh"} {
+		assert.NotContains(logs, secret)
+	}
+	assert.Contains(logs, "user@test.com")
+
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(logs), "\n") {
+		var record map[string]any
+		require.NoError(json.Unmarshal([]byte(line), &record))
+		records = append(records, record)
+	}
+	require.Len(records, 4)
+
+	assert.Equal("INFO", records[0]["level"])
+	assert.Equal("Token", records[0]["endpoint"])
+	assert.Equal("POST", records[0]["method"])
+	assert.EqualValues(200, records[0]["status"])
+	assert.Equal("req-1", records[0]["request_id"])
+	assert.Contains(records[0], "duration")
+
+	assert.Equal("DEBUG", records[1]["level"])
+	assert.Contains(records[1]["request_body"], `"password":"[REDACTED]"`)
+	assert.Contains(records[1]["response_body"], `"access_token":"[REDACTED]"`)
+
+	assert.Equal("WARN", records[2]["level"])
+	assert.Equal("AdminGetUser", records[2]["endpoint"])
+	assert.EqualValues(404, records[2]["status"])
+	assert.Equal("user_not_found", records[2]["error_code"])
+
+	// Error responses from admin endpoints are decoded like any other, rather
+	// than embedding the raw body in the error.
+	var apiErr *endpoints.APIError
+	require.True(errors.As(err, &apiErr))
+	assert.Equal("User not found", apiErr.Message)
+}
+
+func TestLoggerRedactsQuery(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"code":403,"error_code":"otp_expired","msg":"Token has expired or is invalid"}`))
+	}))
+	defer srv.Close()
+
+	var buf bytes.Buffer
+	client := endpoints.New("", "api_key").
+		WithCustomAuthURL(srv.URL).
+		WithLogger(slog.New(slog.NewTextHandler(&buf, nil)))
+
+	_, err := client.Verify(ctx, types.VerifyRequest{
+		Type:       types.VerificationTypeSignup,
+		Token:      "secret-token",
+		RedirectTo: "http://localhost:3000",
+	})
+	assert.Error(err)
+	assert.NotContains(buf.String(), "secret-token")
+	assert.Contains(buf.String(), "error_code=otp_expired")
+}
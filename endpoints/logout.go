@@ -3,6 +3,8 @@ package endpoints
 import (
 	"context"
 	"net/http"
+
+	"github.com/mrehanabbasi/supabase-auth-go/types"
 )
 
 const logoutPath = "/logout"
@@ -13,13 +15,47 @@ const logoutPath = "/logout"
 //
 // This will revoke all refresh tokens for the user. Remember that the JWT
 // tokens will still be valid for stateless auth until they expires.
+//
+// If a session store is configured, the stored session is deleted.
 func (c *Client) Logout(ctx context.Context) error {
-	r, err := c.newRequest(ctx, logoutPath, http.MethodPost, nil)
+	return c.logout(ctx, "Logout", types.LogoutRequest{})
+}
+
+// POST /logout?scope={scope}
+//
+// Logout a user with the given scope (Requires authentication).
+//
+// Scope is one of global, local or others, and defaults to global, which is
+// the same as Logout. Local revokes only the current session, e.g. to sign
+// out of this device, and others revokes every session except the current
+// one.
+//
+// Unless the scope is others, the stored session is deleted if a session
+// store is configured.
+func (c *Client) LogoutWithScope(ctx context.Context, req types.LogoutRequest) error {
+	return c.logout(ctx, "LogoutWithScope", req)
+}
+
+// logout signs out with the given scope, reporting the request as endpoint.
+func (c *Client) logout(ctx context.Context, endpoint string, req types.LogoutRequest) error {
+	switch req.Scope {
+	case "", types.LogoutScopeGlobal, types.LogoutScopeLocal, types.LogoutScopeOthers:
+	default:
+		return types.ErrInvalidLogoutRequest
+	}
+
+	r, err := c.newRequest(ctx, endpoint, logoutPath, http.MethodPost, nil)
 	if err != nil {
 		return newRequestCreationError(err)
 	}
 
-	resp, err := c.client.Do(r)
+	if req.Scope != "" {
+		q := r.URL.Query()
+		q.Add("scope", string(req.Scope))
+		r.URL.RawQuery = q.Encode()
+	}
+
+	resp, err := c.do(r)
 	if err != nil {
 		return newRequestDispatchError(err)
 	}
@@ -29,5 +65,12 @@ func (c *Client) Logout(ctx context.Context) error {
 		return handleErrorResponse(resp)
 	}
 
-	return nil
+	// The current session is still valid after signing out other sessions.
+	if req.Scope == types.LogoutScopeOthers {
+		return nil
+	}
+
+	c.emit(types.AuthChangeEventSignedOut, nil)
+
+	return c.deleteSession(ctx)
 }
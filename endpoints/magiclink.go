@@ -20,26 +20,60 @@ const magiclinkPath = "/magiclink"
 // address which they can use to redeem an access_token.
 //
 // By default Magic Links can only be sent once every 60 seconds.
+//
+// If FlowType is pkce, the client must have a verifier store, which the
+// verifier is saved into. Otherwise ErrVerifierStoreRequired is returned.
+// Use MagiclinkWithPKCE to get the verifier back instead.
 func (c *Client) Magiclink(ctx context.Context, req types.MagiclinkRequest) error {
+	if err := c.requireVerifierStore(req.FlowType); err != nil {
+		return err
+	}
+	_, err := c.MagiclinkWithPKCE(ctx, req)
+	return err
+}
+
+// MagiclinkWithPKCE is like Magiclink, but returns the verifier of the PKCE flow
+// started if FlowType is pkce, to exchange the auth code with. If the client
+// has a verifier store, the verifier is also saved into it. It is reported
+// under the same endpoint name as Magiclink, so the two share rate limits.
+func (c *Client) MagiclinkWithPKCE(ctx context.Context, req types.MagiclinkRequest) (*types.MagiclinkResponse, error) {
+	challenge, verifier, err := newPKCEChallenge(req.FlowType)
+	if err != nil {
+		return nil, err
+	}
+	state, redirectTo, err := c.storeVerifier(ctx, verifier, req.RedirectTo)
+	if err != nil {
+		return nil, err
+	}
+
 	body := new(bytes.Buffer)
-	if err := json.NewEncoder(body).Encode(req); err != nil {
-		return newRequestEncodingError(err)
+	payload := struct {
+		types.MagiclinkRequest
+		pkceChallenge
+	}{req, challenge}
+	if err := json.NewEncoder(body).Encode(payload); err != nil {
+		return nil, newRequestEncodingError(err)
 	}
 
-	r, err := c.newRequest(ctx, magiclinkPath, http.MethodPost, body)
+	r, err := c.newRequest(ctx, "Magiclink", magiclinkPath, http.MethodPost, body)
 	if err != nil {
-		return newRequestCreationError(err)
+		return nil, newRequestCreationError(err)
 	}
+	r = withRecipient(r, req.Email)
+	addRedirectTo(r, redirectTo)
 
-	resp, err := c.client.Do(r)
+	resp, err := c.do(r)
 	if err != nil {
-		return newRequestDispatchError(err)
+		return nil, newRequestDispatchError(err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return handleErrorResponse(resp)
+		return nil, handleErrorResponse(resp)
 	}
 
-	return nil
+	return &types.MagiclinkResponse{
+		Verifier: verifier,
+		State:    state,
+	}, nil
 }
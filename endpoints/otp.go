@@ -17,26 +17,60 @@ const otpPath = "/otp"
 //
 // If CreateUser is true, the user will be automatically signed up if the user
 // doesn't exist.
+//
+// If FlowType is pkce, the client must have a verifier store, which the
+// verifier is saved into. Otherwise ErrVerifierStoreRequired is returned.
+// Use OTPWithPKCE to get the verifier back instead.
 func (c *Client) OTP(ctx context.Context, req types.OTPRequest) error {
+	if err := c.requireVerifierStore(req.FlowType); err != nil {
+		return err
+	}
+	_, err := c.OTPWithPKCE(ctx, req)
+	return err
+}
+
+// OTPWithPKCE is like OTP, but returns the verifier of the PKCE flow
+// started if FlowType is pkce, to exchange the auth code with. If the client
+// has a verifier store, the verifier is also saved into it. It is reported
+// under the same endpoint name as OTP, so the two share rate limits.
+func (c *Client) OTPWithPKCE(ctx context.Context, req types.OTPRequest) (*types.OTPResponse, error) {
+	challenge, verifier, err := newPKCEChallenge(req.FlowType)
+	if err != nil {
+		return nil, err
+	}
+	state, redirectTo, err := c.storeVerifier(ctx, verifier, req.RedirectTo)
+	if err != nil {
+		return nil, err
+	}
+
 	body := new(bytes.Buffer)
-	if err := json.NewEncoder(body).Encode(req); err != nil {
-		return newRequestEncodingError(err)
+	payload := struct {
+		types.OTPRequest
+		pkceChallenge
+	}{req, challenge}
+	if err := json.NewEncoder(body).Encode(payload); err != nil {
+		return nil, newRequestEncodingError(err)
 	}
 
-	r, err := c.newRequest(ctx, otpPath, http.MethodPost, body)
+	r, err := c.newRequest(ctx, "OTP", otpPath, http.MethodPost, body)
 	if err != nil {
-		return newRequestCreationError(err)
+		return nil, newRequestCreationError(err)
 	}
+	r = withRecipient(r, recipientOf(req.Email, req.Phone))
+	addRedirectTo(r, redirectTo)
 
-	resp, err := c.client.Do(r)
+	resp, err := c.do(r)
 	if err != nil {
-		return newRequestDispatchError(err)
+		return nil, newRequestDispatchError(err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return handleErrorResponse(resp)
+		return nil, handleErrorResponse(resp)
 	}
 
-	return nil
+	return &types.OTPResponse{
+		Verifier: verifier,
+		State:    state,
+	}, nil
 }
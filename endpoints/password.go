@@ -0,0 +1,75 @@
+package endpoints
+
+import (
+	"context"
+	"strings"
+
+	"github.com/mrehanabbasi/supabase-auth-go/types"
+)
+
+// WeakPasswordError is returned when a password is rejected as too weak,
+// either by the Auth server, wrapped in an APIError, or by the client's
+// PasswordPolicy before the request is sent. It matches ErrWeakPassword.
+type WeakPasswordError struct {
+	// Message is the server's message, or a summary of the reasons if the
+	// password was rejected by the client.
+	Message string
+	Reasons []WeakPasswordReason
+}
+
+func (e *WeakPasswordError) Error() string {
+	if e.Message != "" {
+		return e.Message
+	}
+	reasons := make([]string, len(e.Reasons))
+	for i, reason := range e.Reasons {
+		reasons[i] = string(reason)
+	}
+	return "password is too weak: " + strings.Join(reasons, ", ")
+}
+
+func (e *WeakPasswordError) Unwrap() error {
+	return ErrWeakPassword
+}
+
+// ValidatePassword checks password against policy the way the Auth server
+// does, and returns a *WeakPasswordError listing every rule it breaks.
+//
+// If the policy's breached password checker fails, the password is not
+// rejected for it, and the server is left to decide.
+func ValidatePassword(ctx context.Context, policy types.PasswordPolicy, password string) error {
+	minLength := policy.MinLength
+	if minLength <= 0 {
+		minLength = types.DefaultPasswordMinLength
+	}
+
+	var reasons []WeakPasswordReason
+	if len(password) < minLength {
+		reasons = append(reasons, WeakPasswordReasonLength)
+	}
+	for _, set := range policy.CharacterSets() {
+		if !strings.ContainsAny(password, set) {
+			reasons = append(reasons, WeakPasswordReasonCharacters)
+			break
+		}
+	}
+	if policy.Breached != nil {
+		if breached, err := policy.Breached.IsBreached(ctx, password); err == nil && breached {
+			reasons = append(reasons, WeakPasswordReasonPwned)
+		}
+	}
+
+	if len(reasons) == 0 {
+		return nil
+	}
+	return &WeakPasswordError{Reasons: reasons}
+}
+
+// ValidatePassword checks password against the client's password policy. It
+// always succeeds if the client has none.
+func (c *Client) ValidatePassword(ctx context.Context, password string) error {
+	if c.passwordPolicy == nil {
+		return nil
+	}
+	return ValidatePassword(ctx, *c.passwordPolicy, password)
+}
@@ -0,0 +1,111 @@
+package endpoints_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrehanabbasi/supabase-auth-go/endpoints"
+	"github.com/mrehanabbasi/supabase-auth-go/types"
+)
+
+type breachedFunc func(password string) (bool, error)
+
+func (f breachedFunc) IsBreached(_ context.Context, password string) (bool, error) {
+	return f(password)
+}
+
+func TestValidatePassword(t *testing.T) {
+	ctx := context.Background()
+	breached := breachedFunc(func(password string) (bool, error) {
+		switch password {
+		case "Password1":
+			return true, nil
+		case "Unavailable1":
+			return false, errors.New("service unavailable")
+		}
+		return false, nil
+	})
+
+	tests := []struct {
+		name     string
+		policy   types.PasswordPolicy
+		password string
+		reasons  []endpoints.WeakPasswordReason
+	}{
+		{"default length", types.PasswordPolicy{}, "12345", []endpoints.WeakPasswordReason{endpoints.WeakPasswordReasonLength}},
+		{"default ok", types.PasswordPolicy{}, "123456", nil},
+		{"min length", types.PasswordPolicy{MinLength: 10}, "123456789", []endpoints.WeakPasswordReason{endpoints.WeakPasswordReasonLength}},
+		{"characters", types.PasswordPolicy{RequiredCharacters: types.PasswordCharactersLowerUpperDigits}, "password1", []endpoints.WeakPasswordReason{endpoints.WeakPasswordReasonCharacters}},
+		{"characters ok", types.PasswordPolicy{RequiredCharacters: types.PasswordCharactersLowerUpperDigits}, "Password1", nil},
+		{"symbols", types.PasswordPolicy{RequiredCharacters: types.PasswordCharactersLowerUpperDigitsSymbols}, "Password1", []endpoints.WeakPasswordReason{endpoints.WeakPasswordReasonCharacters}},
+		{"escaped colon", types.PasswordPolicy{RequiredCharacters: types.PasswordCharactersLowerUpperDigitsSymbols}, "Password1:", nil},
+		{"all reasons", types.PasswordPolicy{MinLength: 8, RequiredCharacters: "0123456789", Breached: breachedFunc(func(string) (bool, error) { return true, nil })}, "pass", []endpoints.WeakPasswordReason{endpoints.WeakPasswordReasonLength, endpoints.WeakPasswordReasonCharacters, endpoints.WeakPasswordReasonPwned}},
+		{"breached", types.PasswordPolicy{Breached: breached}, "Password1", []endpoints.WeakPasswordReason{endpoints.WeakPasswordReasonPwned}},
+		{"checker fails open", types.PasswordPolicy{Breached: breached}, "Unavailable1", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := endpoints.ValidatePassword(ctx, tt.policy, tt.password)
+			if tt.reasons == nil {
+				assert.NoError(t, err)
+				return
+			}
+			var weakErr *endpoints.WeakPasswordError
+			require.ErrorAs(t, err, &weakErr)
+			assert.Equal(t, tt.reasons, weakErr.Reasons)
+			assert.ErrorIs(t, err, endpoints.ErrWeakPassword)
+		})
+	}
+}
+
+func TestPasswordPolicy(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	ctx := context.Background()
+
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	client := endpoints.New("", "api_key").WithCustomAuthURL(srv.URL)
+	strict := client.WithPasswordPolicy(types.PasswordPolicy{
+		MinLength:          8,
+		RequiredCharacters: types.PasswordCharactersLettersDigits,
+	})
+
+	weak := "password"
+	_, err := strict.Signup(ctx, types.SignupRequest{Email: "user@test.com", Password: weak})
+	var weakErr *endpoints.WeakPasswordError
+	require.ErrorAs(err, &weakErr)
+	assert.Equal([]endpoints.WeakPasswordReason{endpoints.WeakPasswordReasonCharacters}, weakErr.Reasons)
+	assert.EqualError(err, "password is too weak: characters")
+
+	_, err = strict.UpdateUser(ctx, types.UpdateUserRequest{Password: &weak})
+	assert.ErrorIs(err, endpoints.ErrWeakPassword)
+
+	_, err = strict.AdminCreateUser(ctx, types.AdminCreateUserRequest{Email: "user@test.com", Password: &weak})
+	assert.ErrorIs(err, endpoints.ErrWeakPassword)
+	assert.Equal(0, requests)
+
+	// Requests without a new password, and clients without a policy, are not
+	// checked.
+	_, err = strict.UpdateUser(ctx, types.UpdateUserRequest{Email: "new@test.com"})
+	assert.NoError(err)
+	_, err = client.Signup(ctx, types.SignupRequest{Email: "user@test.com", Password: weak})
+	assert.NoError(err)
+	assert.NoError(client.ValidatePassword(ctx, "x"))
+
+	strong := "password1"
+	_, err = strict.Signup(ctx, types.SignupRequest{Email: "user@test.com", Password: strong})
+	assert.NoError(err)
+	assert.Equal(3, requests)
+}
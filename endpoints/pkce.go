@@ -0,0 +1,128 @@
+package endpoints
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/mrehanabbasi/supabase-auth-go/types"
+)
+
+// ErrVerifierStoreRequired is returned, without sending the request, when
+// Recover, OTP or Magiclink would start a PKCE flow on a client without a
+// verifier store, which would lose the verifier.
+var ErrVerifierStoreRequired = errors.New("a verifier store is required to start a PKCE flow without returning the verifier")
+
+// pkceChallenge is embedded alongside a request in the JSON body of the
+// endpoints that send an email or SMS link, so that the link carries an auth
+// code to exchange with ExchangeCodeForSession instead of tokens.
+type pkceChallenge struct {
+	CodeChallenge       string `json:"code_challenge,omitempty"`
+	CodeChallengeMethod string `json:"code_challenge_method,omitempty"`
+}
+
+// newPKCEChallenge generates PKCE parameters if flowType is pkce. It returns
+// the challenge to send, and the verifier to return to the caller.
+func newPKCEChallenge(flowType types.FlowType) (pkceChallenge, string, error) {
+	if flowType != types.FlowPKCE {
+		return pkceChallenge{}, "", nil
+	}
+
+	pkce, err := generatePKCEParams()
+	if err != nil {
+		return pkceChallenge{}, "", err
+	}
+
+	return pkceChallenge{
+		CodeChallenge:       pkce.Challenge,
+		CodeChallengeMethod: pkce.ChallengeMethod,
+	}, pkce.Verifier, nil
+}
+
+// addPKCEChallenge adds the challenge of a new PKCE flow to the query q of
+// the endpoints that redirect to an OAuth provider, if flowType is pkce. It
+// returns the verifier to return to the caller.
+func addPKCEChallenge(q url.Values, flowType types.FlowType) (string, error) {
+	challenge, verifier, err := newPKCEChallenge(flowType)
+	if err != nil || verifier == "" {
+		return "", err
+	}
+	q.Add("code_challenge", challenge.CodeChallenge)
+	q.Add("code_challenge_method", challenge.CodeChallengeMethod)
+	return verifier, nil
+}
+
+// requireVerifierStore returns ErrVerifierStoreRequired if flowType is pkce
+// and the client has no verifier store to save the verifier into.
+func (c *Client) requireVerifierStore(flowType types.FlowType) error {
+	if flowType == types.FlowPKCE && c.verifierStore == nil {
+		return ErrVerifierStoreRequired
+	}
+	return nil
+}
+
+// storeVerifier saves verifier into the verifier store under a new random
+// state, if a store is configured and a flow was started. It returns the
+// state, and redirectTo with the state added to it.
+func (c *Client) storeVerifier(ctx context.Context, verifier string, redirectTo string) (string, string, error) {
+	if c.verifierStore == nil || verifier == "" {
+		return "", redirectTo, nil
+	}
+
+	data := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, data); err != nil {
+		return "", "", err
+	}
+	state := base64.RawURLEncoding.EncodeToString(data)
+
+	if err := c.verifierStore.Save(ctx, state, verifier); err != nil {
+		return "", "", newVerifierStoreError(err)
+	}
+
+	if redirectTo == "" {
+		return state, "", nil
+	}
+	u, err := url.Parse(redirectTo)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid redirect URL: %w", err)
+	}
+	q := u.Query()
+	q.Set(types.StateParam, state)
+	u.RawQuery = q.Encode()
+
+	return state, u.String(), nil
+}
+
+// takeVerifier loads and deletes the verifier stored under state, in one
+// step, so that it can only be used once.
+func (c *Client) takeVerifier(ctx context.Context, state string) (string, error) {
+	if c.verifierStore == nil {
+		return "", fmt.Errorf("%w: no verifier store is configured", types.ErrVerifierNotFound)
+	}
+
+	verifier, err := c.verifierStore.Take(ctx, state)
+	if err != nil {
+		if errors.Is(err, types.ErrVerifierNotFound) {
+			return "", err
+		}
+		return "", newVerifierStoreError(err)
+	}
+
+	return verifier, nil
+}
+
+// addRedirectTo sets the redirect_to query parameter used by the endpoints
+// that send an email or SMS link.
+func addRedirectTo(r *http.Request, redirectTo string) {
+	if redirectTo == "" {
+		return
+	}
+	q := r.URL.Query()
+	q.Set("redirect_to", redirectTo)
+	r.URL.RawQuery = q.Encode()
+}
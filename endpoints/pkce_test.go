@@ -0,0 +1,216 @@
+package endpoints_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrehanabbasi/supabase-auth-go/endpoints"
+	"github.com/mrehanabbasi/supabase-auth-go/pkce"
+	"github.com/mrehanabbasi/supabase-auth-go/types"
+)
+
+func TestPKCEFlow(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	ctx := context.Background()
+
+	var challenge, method string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		switch r.URL.Path {
+		case "/recover":
+			challenge, _ = body["code_challenge"].(string)
+			method, _ = body["code_challenge_method"].(string)
+			// The flow type itself is not sent.
+			assert.NotContains(body, "FlowType")
+			assert.Equal("user@test.com", body["email"])
+			_, _ = w.Write([]byte(`{}`))
+		case "/token":
+			assert.Equal("pkce", r.URL.Query().Get("grant_type"))
+			assert.Equal("code", body["auth_code"])
+			sha := sha256.Sum256([]byte(body["code_verifier"].(string)))
+			if base64.RawURLEncoding.EncodeToString(sha[:]) != challenge {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"code":400,"error_code":"bad_code_verifier","msg":"code challenge does not match"}`))
+				return
+			}
+			_ = json.NewEncoder(w).Encode(types.Session{AccessToken: "access"})
+		}
+	}))
+	defer srv.Close()
+
+	client := endpoints.New("", "api_key").WithCustomAuthURL(srv.URL)
+
+	// Without PKCE no challenge is sent.
+	resp, err := client.RecoverWithPKCE(ctx, types.RecoverRequest{Email: "user@test.com"})
+	require.NoError(err)
+	assert.Empty(resp.Verifier)
+	assert.Empty(challenge)
+
+	// Recover has no way to return the verifier without a verifier store.
+	err = client.Recover(ctx, types.RecoverRequest{
+		Email:    "user@test.com",
+		FlowType: types.FlowPKCE,
+	})
+	assert.ErrorIs(err, endpoints.ErrVerifierStoreRequired)
+	assert.Empty(challenge)
+
+	resp, err = client.RecoverWithPKCE(ctx, types.RecoverRequest{
+		Email:    "user@test.com",
+		FlowType: types.FlowPKCE,
+	})
+	require.NoError(err)
+	require.NotEmpty(resp.Verifier)
+	assert.NotEmpty(challenge)
+	assert.Equal("S256", method)
+
+	_, err = client.ExchangeCodeForSession(ctx, types.ExchangeCodeForSessionRequest{
+		AuthCode:     "code",
+		CodeVerifier: "wrong",
+	})
+	assert.Error(err)
+
+	token, err := client.ExchangeCodeForSession(ctx, types.ExchangeCodeForSessionRequest{
+		AuthCode:     "code",
+		CodeVerifier: resp.Verifier,
+	})
+	require.NoError(err)
+	assert.Equal("access", token.AccessToken)
+
+	_, err = client.ExchangeCodeForSession(ctx, types.ExchangeCodeForSessionRequest{})
+	assert.ErrorIs(err, types.ErrInvalidTokenRequest)
+}
+
+func TestVerifierStore(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	ctx := context.Background()
+
+	var challenge, redirectTo string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/authorize":
+			challenge = r.URL.Query().Get("code_challenge")
+			redirectTo = r.URL.Query().Get("redirect_to")
+			w.Header().Set("Location", "https://github.com/login/oauth/authorize")
+			w.WriteHeader(http.StatusFound)
+		case "/token":
+			var body map[string]interface{}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			sha := sha256.Sum256([]byte(body["code_verifier"].(string)))
+			if base64.RawURLEncoding.EncodeToString(sha[:]) != challenge {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"code":400,"error_code":"bad_code_verifier","msg":"code challenge does not match"}`))
+				return
+			}
+			_ = json.NewEncoder(w).Encode(types.Session{AccessToken: "access"})
+		}
+	}))
+	defer srv.Close()
+
+	client := endpoints.New("", "api_key").
+		WithCustomAuthURL(srv.URL).
+		WithVerifierStore(pkce.NewMemoryStore(0))
+
+	resp, err := client.Authorize(ctx, types.AuthorizeRequest{
+		Provider:   types.ProviderGitHub,
+		RedirectTo: "http://localhost:3000/callback?next=%2Fhome",
+		FlowType:   types.FlowPKCE,
+	})
+	require.NoError(err)
+	require.NotEmpty(resp.State)
+
+	// The state is carried back through the redirect URL.
+	u, err := url.Parse(redirectTo)
+	require.NoError(err)
+	assert.Equal("/callback", u.Path)
+	assert.Equal("/home", u.Query().Get("next"))
+	assert.Equal(resp.State, u.Query().Get(types.StateParam))
+
+	token, err := client.ExchangeCodeForSession(ctx, types.ExchangeCodeForSessionRequest{
+		AuthCode: "code",
+		State:    resp.State,
+	})
+	require.NoError(err)
+	assert.Equal("access", token.AccessToken)
+
+	// The verifier can only be used once.
+	_, err = client.ExchangeCodeForSession(ctx, types.ExchangeCodeForSessionRequest{
+		AuthCode: "code",
+		State:    resp.State,
+	})
+	assert.ErrorIs(err, types.ErrVerifierNotFound)
+
+	// Without a store, the state cannot be used.
+	_, err = endpoints.New("", "api_key").WithCustomAuthURL(srv.URL).ExchangeCodeForSession(ctx, types.ExchangeCodeForSessionRequest{
+		AuthCode: "code",
+		State:    resp.State,
+	})
+	assert.ErrorIs(err, types.ErrVerifierNotFound)
+}
+
+func TestVerifierStoreConcurrentExchange(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	ctx := context.Background()
+
+	var exchanged atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/authorize":
+			w.Header().Set("Location", "https://github.com/login/oauth/authorize")
+			w.WriteHeader(http.StatusFound)
+		case "/token":
+			exchanged.Add(1)
+			_ = json.NewEncoder(w).Encode(types.Session{AccessToken: "access"})
+		}
+	}))
+	defer srv.Close()
+
+	client := endpoints.New("", "api_key").
+		WithCustomAuthURL(srv.URL).
+		WithVerifierStore(pkce.NewMemoryStore(0))
+
+	resp, err := client.Authorize(ctx, types.AuthorizeRequest{
+		Provider: types.ProviderGitHub,
+		FlowType: types.FlowPKCE,
+	})
+	require.NoError(err)
+
+	// Of the callbacks racing to exchange the same state, only one gets the
+	// verifier.
+	var wg sync.WaitGroup
+	var succeeded atomic.Int32
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := client.ExchangeCodeForSession(ctx, types.ExchangeCodeForSessionRequest{
+				AuthCode: "code",
+				State:    resp.State,
+			})
+			if err == nil {
+				succeeded.Add(1)
+			} else {
+				assert.ErrorIs(err, types.ErrVerifierNotFound)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.EqualValues(1, succeeded.Load())
+	assert.EqualValues(1, exchanged.Load())
+}
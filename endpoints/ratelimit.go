@@ -0,0 +1,211 @@
+package endpoints
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mrehanabbasi/supabase-auth-go/types"
+)
+
+var ErrClientRateLimited = errors.New("rate limited by client")
+
+// RateLimitError is returned, without sending the request, when a call would
+// exceed the client's RateLimitPolicy. It matches ErrClientRateLimited.
+type RateLimitError struct {
+	// Endpoint is the client method that was called, e.g. "Recover".
+	Endpoint string
+	// Recipient is the email address or phone number that is on cooldown, or
+	// empty if the endpoint's limit was reached.
+	Recipient string
+	// RetryAfter is how long until the call will be allowed.
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	reason := "endpoint limit reached"
+	if e.Recipient != "" {
+		reason = "recipient on cooldown"
+	}
+	return fmt.Sprintf("supabase-auth - %s rate limited by client: %s, retry in %s", e.Endpoint, reason, e.RetryAfter.Round(time.Millisecond))
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return ErrClientRateLimited
+}
+
+type recipientKey struct{}
+
+// withRecipient records the email address or phone number r sends a message
+// to, for the client's recipient cooldown.
+func withRecipient(r *http.Request, recipient string) *http.Request {
+	if recipient == "" {
+		return r
+	}
+	return r.WithContext(context.WithValue(r.Context(), recipientKey{}, recipient))
+}
+
+// recipientOf returns whichever of email and phone a message is sent to.
+func recipientOf(email string, phone string) string {
+	if email != "" {
+		return email
+	}
+	return phone
+}
+
+// rateLimiter enforces a RateLimitPolicy. It is shared by the copies of the
+// client it was created for.
+type rateLimiter struct {
+	policy types.RateLimitPolicy
+
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+	// cooldowns holds when each recipient may next be sent a message, by any
+	// endpoint.
+	cooldowns map[string]time.Time
+	pruned    time.Time
+}
+
+func newRateLimiter(policy types.RateLimitPolicy) *rateLimiter {
+	l := &rateLimiter{
+		policy:    policy,
+		buckets:   make(map[string]*tokenBucket),
+		cooldowns: make(map[string]time.Time),
+	}
+	for endpoint, limit := range policy.Endpoints {
+		if limit.Limit <= 0 || limit.Interval <= 0 {
+			continue
+		}
+		burst := limit.Burst
+		if burst <= 0 {
+			burst = limit.Limit
+		}
+		l.buckets[endpoint] = &tokenBucket{
+			tokens: float64(burst),
+			burst:  float64(burst),
+			rate:   float64(limit.Limit) / limit.Interval.Seconds(),
+		}
+	}
+	return l
+}
+
+type tokenBucket struct {
+	tokens float64
+	burst  float64
+	// rate is the number of tokens added per second.
+	rate float64
+	last time.Time
+}
+
+// refill adds the tokens earned since the last call, and returns how long
+// until a token is available.
+func (b *tokenBucket) refill(now time.Time) time.Duration {
+	if !b.last.IsZero() {
+		b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	}
+	b.last = now
+	if b.tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
+
+// reserve waits until a call to endpoint sending to recipient is allowed, and
+// takes a token and starts the recipient's cooldown for it. The returned
+// function must be called once the call is done, with whether the message
+// was accepted. If it was not, the cooldown is lifted again.
+//
+// If the policy does not allow waiting, or the wait would outlast the
+// context's deadline, a *RateLimitError is returned instead.
+func (l *rateLimiter) reserve(ctx context.Context, endpoint string, recipient string) (func(accepted bool), error) {
+	key := ""
+	if recipient != "" && l.policy.RecipientCooldown > 0 {
+		key = strings.ToLower(strings.TrimSpace(recipient))
+	}
+
+	for {
+		l.mu.Lock()
+		now := time.Now()
+		bucket := l.buckets[endpoint]
+		var wait time.Duration
+		if bucket != nil {
+			wait = bucket.refill(now)
+		}
+		limitErr := &RateLimitError{Endpoint: endpoint}
+		if until := l.cooldowns[key]; key != "" && until.Sub(now) > wait {
+			wait = until.Sub(now)
+			limitErr.Recipient = recipient
+		}
+
+		if wait <= 0 {
+			if bucket != nil {
+				bucket.tokens--
+			}
+			if key == "" {
+				l.mu.Unlock()
+				return func(bool) {}, nil
+			}
+			until := now.Add(l.policy.RecipientCooldown)
+			l.cooldowns[key] = until
+			l.prune(now)
+			l.mu.Unlock()
+			return func(accepted bool) {
+				if accepted {
+					return
+				}
+				l.mu.Lock()
+				defer l.mu.Unlock()
+				if l.cooldowns[key].Equal(until) {
+					delete(l.cooldowns, key)
+				}
+			}, nil
+		}
+		l.mu.Unlock()
+
+		limitErr.RetryAfter = wait
+		if !l.policy.Wait {
+			return nil, limitErr
+		}
+		if deadline, ok := ctx.Deadline(); ok && deadline.Before(now.Add(wait)) {
+			return nil, limitErr
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// prune forgets cooldowns that have ended, at most once per cooldown.
+func (l *rateLimiter) prune(now time.Time) {
+	if now.Sub(l.pruned) < l.policy.RecipientCooldown {
+		return
+	}
+	l.pruned = now
+	for key, until := range l.cooldowns {
+		if !until.After(now) {
+			delete(l.cooldowns, key)
+		}
+	}
+}
+
+// rateLimit sends r with send once the client's rate limiter allows it.
+func (c *Client) rateLimit(endpoint string, r *http.Request, send func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	recipient, _ := r.Context().Value(recipientKey{}).(string)
+	done, err := c.rateLimiter.reserve(r.Context(), endpoint, recipient)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := send(r)
+	// A 429 means the Auth server is keeping a cooldown of its own.
+	done(err == nil && (resp.StatusCode < 400 || resp.StatusCode == http.StatusTooManyRequests))
+	return resp, err
+}
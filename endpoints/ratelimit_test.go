@@ -0,0 +1,164 @@
+package endpoints_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrehanabbasi/supabase-auth-go/endpoints"
+	"github.com/mrehanabbasi/supabase-auth-go/types"
+)
+
+func TestRateLimitPolicy(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	ctx := context.Background()
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if r.URL.Path == "/invite" {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			_, _ = w.Write([]byte(`{"code":422,"error_code":"email_address_invalid","msg":"Email address is invalid"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	client := endpoints.New("", "api_key").WithCustomAuthURL(srv.URL).WithRateLimitPolicy(types.RateLimitPolicy{
+		Endpoints: map[string]types.RateLimit{
+			"GetSettings": {Limit: 2, Interval: time.Hour},
+		},
+		RecipientCooldown: time.Hour,
+	})
+
+	// The endpoint's bucket allows a burst of 2.
+	_, err := client.GetSettings(ctx)
+	require.NoError(err)
+	_, err = client.GetSettings(ctx)
+	require.NoError(err)
+	_, err = client.GetSettings(ctx)
+	var limitErr *endpoints.RateLimitError
+	require.ErrorAs(err, &limitErr)
+	assert.Equal("GetSettings", limitErr.Endpoint)
+	assert.Empty(limitErr.Recipient)
+	assert.InDelta(30*time.Minute, limitErr.RetryAfter, float64(time.Second))
+	assert.ErrorIs(err, endpoints.ErrClientRateLimited)
+	assert.True(endpoints.IsRateLimited(err))
+	assert.Equal(int32(2), calls.Load())
+
+	// Other endpoints are not limited.
+	_, err = client.HealthCheck(ctx)
+	require.NoError(err)
+
+	// Each recipient has a cooldown, shared by copies of the client.
+	err = client.Recover(ctx, types.RecoverRequest{Email: "user@test.com"})
+	require.NoError(err)
+	err = client.WithToken("token").Recover(ctx, types.RecoverRequest{Email: "User@Test.com"})
+	require.ErrorAs(err, &limitErr)
+	assert.Equal("Recover", limitErr.Endpoint)
+	assert.Equal("User@Test.com", limitErr.Recipient)
+	assert.InDelta(time.Hour, limitErr.RetryAfter, float64(time.Second))
+	_, err = client.RecoverWithPKCE(ctx, types.RecoverRequest{Email: "user@test.com"})
+	require.ErrorAs(err, &limitErr)
+	assert.Equal("Recover", limitErr.Endpoint)
+	err = client.Recover(ctx, types.RecoverRequest{Email: "other@test.com"})
+	require.NoError(err)
+	err = client.OTP(ctx, types.OTPRequest{Phone: "+15555550199"})
+	require.NoError(err)
+	_, err = client.Resend(ctx, types.ResendRequest{Type: types.ResendTypeSMS, Phone: "+15555550100"})
+	require.NoError(err)
+	_, err = client.Resend(ctx, types.ResendRequest{Type: types.ResendTypeSMS, Phone: "+15555550100"})
+	assert.ErrorAs(err, &limitErr)
+
+	// Messages the server rejects do not start a cooldown.
+	_, err = client.Invite(ctx, types.InviteRequest{Email: "invalid@test.com"})
+	assert.ErrorIs(err, endpoints.ErrEmailAddressInvalid)
+	_, err = client.Invite(ctx, types.InviteRequest{Email: "invalid@test.com"})
+	assert.ErrorIs(err, endpoints.ErrEmailAddressInvalid)
+
+	// Setting the policy again starts afresh.
+	err = client.WithRateLimitPolicy(types.DefaultRateLimitPolicy()).Recover(ctx, types.RecoverRequest{Email: "user@test.com"})
+	assert.NoError(err)
+}
+
+func TestRateLimitPolicyRecipientCooldown(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	ctx := context.Background()
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	client := endpoints.New("", "api_key").WithCustomAuthURL(srv.URL).WithRateLimitPolicy(types.RateLimitPolicy{
+		RecipientCooldown: time.Hour,
+	})
+
+	// The cooldown holds back every endpoint that sends a message, as the Auth
+	// server's does.
+	err := client.Magiclink(ctx, types.MagiclinkRequest{Email: "user@test.com"})
+	require.NoError(err)
+	var limitErr *endpoints.RateLimitError
+	err = client.Recover(ctx, types.RecoverRequest{Email: "user@test.com"})
+	require.ErrorAs(err, &limitErr)
+	assert.Equal("Recover", limitErr.Endpoint)
+	assert.Equal("user@test.com", limitErr.Recipient)
+	err = client.OTP(ctx, types.OTPRequest{Email: "user@test.com"})
+	assert.ErrorAs(err, &limitErr)
+	_, err = client.Resend(ctx, types.ResendRequest{Type: types.ResendTypeSignup, Email: "user@test.com"})
+	assert.ErrorAs(err, &limitErr)
+	assert.Equal(int32(1), calls.Load())
+}
+
+func TestRateLimitPolicyWait(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	ctx := context.Background()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	client := endpoints.New("", "api_key").WithCustomAuthURL(srv.URL).WithRateLimitPolicy(types.RateLimitPolicy{
+		Endpoints: map[string]types.RateLimit{
+			"GetSettings": {Limit: 1, Interval: 50 * time.Millisecond},
+		},
+		RecipientCooldown: 50 * time.Millisecond,
+		Wait:              true,
+	})
+
+	start := time.Now()
+	for range 3 {
+		_, err := client.GetSettings(ctx)
+		require.NoError(err)
+	}
+	assert.GreaterOrEqual(time.Since(start), 90*time.Millisecond)
+
+	start = time.Now()
+	for range 2 {
+		err := client.Magiclink(ctx, types.MagiclinkRequest{Email: "user@test.com"})
+		require.NoError(err)
+	}
+	assert.GreaterOrEqual(time.Since(start), 40*time.Millisecond)
+
+	// Calls that cannot be allowed before their deadline fail straight away.
+	err := client.Magiclink(ctx, types.MagiclinkRequest{Email: "user@test.com"})
+	require.NoError(err)
+	deadlineCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	err = client.Magiclink(deadlineCtx, types.MagiclinkRequest{Email: "user@test.com"})
+	var limitErr *endpoints.RateLimitError
+	assert.ErrorAs(err, &limitErr)
+}
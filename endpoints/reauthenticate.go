@@ -13,12 +13,12 @@ const reauthenticatePath = "/reauthenticate"
 // requires the user to be logged in / authenticated first. The user needs to
 // have either an email or phone number for the nonce to be sent successfully.
 func (c *Client) Reauthenticate(ctx context.Context) error {
-	r, err := c.newRequest(ctx, reauthenticatePath, http.MethodGet, nil)
+	r, err := c.newRequest(ctx, "Reauthenticate", reauthenticatePath, http.MethodGet, nil)
 	if err != nil {
 		return newRequestCreationError(err)
 	}
 
-	resp, err := c.client.Do(r)
+	resp, err := c.do(r)
 	if err != nil {
 		return newRequestDispatchError(err)
 	}
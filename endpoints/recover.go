@@ -17,26 +17,60 @@ const recoverPath = "/recover"
 // on email address.
 //
 // By default recovery links can only be sent once every 60 seconds.
+//
+// If FlowType is pkce, the client must have a verifier store, which the
+// verifier is saved into. Otherwise ErrVerifierStoreRequired is returned.
+// Use RecoverWithPKCE to get the verifier back instead.
 func (c *Client) Recover(ctx context.Context, req types.RecoverRequest) error {
+	if err := c.requireVerifierStore(req.FlowType); err != nil {
+		return err
+	}
+	_, err := c.RecoverWithPKCE(ctx, req)
+	return err
+}
+
+// RecoverWithPKCE is like Recover, but returns the verifier of the PKCE flow
+// started if FlowType is pkce, to exchange the auth code with. If the client
+// has a verifier store, the verifier is also saved into it. It is reported
+// under the same endpoint name as Recover, so the two share rate limits.
+func (c *Client) RecoverWithPKCE(ctx context.Context, req types.RecoverRequest) (*types.RecoverResponse, error) {
+	challenge, verifier, err := newPKCEChallenge(req.FlowType)
+	if err != nil {
+		return nil, err
+	}
+	state, redirectTo, err := c.storeVerifier(ctx, verifier, req.RedirectTo)
+	if err != nil {
+		return nil, err
+	}
+
 	body := new(bytes.Buffer)
-	if err := json.NewEncoder(body).Encode(req); err != nil {
-		return newRequestEncodingError(err)
+	payload := struct {
+		types.RecoverRequest
+		pkceChallenge
+	}{req, challenge}
+	if err := json.NewEncoder(body).Encode(payload); err != nil {
+		return nil, newRequestEncodingError(err)
 	}
 
-	r, err := c.newRequest(ctx, recoverPath, http.MethodPost, body)
+	r, err := c.newRequest(ctx, "Recover", recoverPath, http.MethodPost, body)
 	if err != nil {
-		return newRequestCreationError(err)
+		return nil, newRequestCreationError(err)
 	}
+	r = withRecipient(r, req.Email)
+	addRedirectTo(r, redirectTo)
 
-	resp, err := c.client.Do(r)
+	resp, err := c.do(r)
 	if err != nil {
-		return newRequestDispatchError(err)
+		return nil, newRequestDispatchError(err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return handleErrorResponse(resp)
+		return nil, handleErrorResponse(resp)
 	}
 
-	return nil
+	return &types.RecoverResponse{
+		Verifier: verifier,
+		State:    state,
+	}, nil
 }
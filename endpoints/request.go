@@ -2,11 +2,20 @@ package endpoints
 
 import (
 	"context"
+	"errors"
 	"io"
 	"net/http"
+	"time"
+
+	"github.com/mrehanabbasi/supabase-auth-go/types"
 )
 
-func (c *Client) newRequest(ctx context.Context, path string, method string, body io.Reader) (*http.Request, error) {
+var errNoResponse = errors.New("interceptor returned neither a response nor an error")
+
+type endpointKey struct{}
+
+func (c *Client) newRequest(ctx context.Context, endpoint string, path string, method string, body io.Reader) (*http.Request, error) {
+	ctx = context.WithValue(ctx, endpointKey{}, endpoint)
 	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
 	if err != nil {
 		return nil, err
@@ -22,3 +31,92 @@ func (c *Client) newRequest(ctx context.Context, path string, method string, bod
 
 	return req, nil
 }
+
+// do sends r with the client's HTTP client. See doWith.
+func (c *Client) do(r *http.Request) (*http.Response, error) {
+	return c.doWith(c.client, r)
+}
+
+// doWith passes r through the client's interceptors, in the order they were
+// added, and then sends it with client, retrying according to the client's
+// retry policy. If the client has a logger, the request is logged once the
+// retries are done. If it has an observer, the whole call is reported to it.
+// If it has a circuit breaker, nothing is sent while the circuit is open.
+// If it has a rate limiter, nothing is sent until the limiter allows it.
+func (c *Client) doWith(client *http.Client, r *http.Request) (*http.Response, error) {
+	endpoint, _ := r.Context().Value(endpointKey{}).(string)
+
+	send := func(req *http.Request) (*http.Response, error) {
+		return c.retry(client, req)
+	}
+	if c.logger != nil {
+		retry := send
+		send = func(req *http.Request) (*http.Response, error) {
+			return c.logCall(endpoint, req, retry)
+		}
+	}
+	for i := len(c.interceptors) - 1; i >= 0; i-- {
+		interceptor, next := c.interceptors[i], send
+		send = func(req *http.Request) (*http.Response, error) {
+			return interceptor(endpoint, req, next)
+		}
+	}
+
+	intercepted := send
+	send = func(req *http.Request) (*http.Response, error) {
+		resp, err := intercepted(req)
+		if resp == nil && err == nil {
+			return nil, errNoResponse
+		}
+		return resp, err
+	}
+
+	if c.observer != nil {
+		observed := send
+		send = func(req *http.Request) (*http.Response, error) {
+			return c.observe(endpoint, req, observed)
+		}
+	}
+	if c.breaker != nil {
+		guarded := send
+		send = func(req *http.Request) (*http.Response, error) {
+			return c.circuit(endpoint, req, guarded)
+		}
+	}
+	if c.rateLimiter != nil {
+		return c.rateLimit(endpoint, r, send)
+	}
+	return send(r)
+}
+
+// observe sends r with send, reporting a span, a count and the latency to
+// the client's observer.
+func (c *Client) observe(endpoint string, r *http.Request, send func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	ctx, span := c.observer.StartSpan(r.Context(), endpoint)
+
+	start := time.Now()
+	resp, err := send(r.WithContext(ctx))
+	duration := time.Since(start)
+
+	status := 0
+	outcome := types.OutcomeSuccess
+	switch {
+	case err != nil && ctx.Err() != nil:
+		outcome = types.OutcomeCanceled
+	case err != nil:
+		outcome = types.OutcomeNetworkError
+	case resp.StatusCode >= 500:
+		outcome = types.OutcomeServerError
+	case resp.StatusCode >= 400:
+		outcome = types.OutcomeClientError
+	}
+	if resp != nil {
+		status = resp.StatusCode
+	}
+
+	span.End(outcome, status, err)
+	c.observer.IncCounter(endpoint, outcome)
+	c.observer.ObserveLatency(endpoint, outcome, duration)
+
+	return resp, err
+}
@@ -0,0 +1,99 @@
+package endpoints
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/mrehanabbasi/supabase-auth-go/types"
+)
+
+const resendPath = "/resend"
+
+func validateResendRequest(req types.ResendRequest) error {
+	switch req.Type {
+	case types.ResendTypeSignup, types.ResendTypeEmailChange:
+		if req.Email == "" {
+			return types.NewErrInvalidResendRequest("email must be provided if Type is signup or email_change")
+		}
+		if req.Phone != "" {
+			return types.NewErrInvalidResendRequest("phone must not be provided if Type is signup or email_change")
+		}
+	case types.ResendTypeSMS, types.ResendTypePhoneChange:
+		if req.Phone == "" {
+			return types.NewErrInvalidResendRequest("phone must be provided if Type is sms or phone_change")
+		}
+		if req.Email != "" {
+			return types.NewErrInvalidResendRequest("email must not be provided if Type is sms or phone_change")
+		}
+		if req.EmailRedirectTo != "" {
+			return types.NewErrInvalidResendRequest("email_redirect_to must not be provided if Type is sms or phone_change")
+		}
+	default:
+		return types.NewErrInvalidResendRequest("type must be one of signup, email_change, sms or phone_change")
+	}
+
+	return nil
+}
+
+// POST /resend
+//
+// Resend an existing signup confirmation, email change, SMS OTP or phone
+// change OTP to the user. Provide Email for the signup and email_change
+// types, and Phone for the sms and phone_change types.
+//
+// Sending is rate limited by the Auth server. If the limit is reached for an
+// email, ErrEmailSendLimitExceeded is returned. Use IsRateLimited to check
+// for any rate limit, including those for SMS.
+func (c *Client) Resend(ctx context.Context, req types.ResendRequest) (*types.ResendResponse, error) {
+	if err := validateResendRequest(req); err != nil {
+		return nil, err
+	}
+
+	body := new(bytes.Buffer)
+	if err := json.NewEncoder(body).Encode(req); err != nil {
+		return nil, newRequestEncodingError(err)
+	}
+
+	r, err := c.newRequest(ctx, "Resend", resendPath, http.MethodPost, body)
+	if err != nil {
+		return nil, newRequestCreationError(err)
+	}
+	r = withRecipient(r, recipientOf(req.Email, req.Phone))
+
+	if req.EmailRedirectTo != "" {
+		q := r.URL.Query()
+		q.Add("redirect_to", req.EmailRedirectTo)
+		r.URL.RawQuery = q.Encode()
+	}
+
+	resp, err := c.do(r)
+	if err != nil {
+		return nil, newRequestDispatchError(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusTooManyRequests && req.Email != "" {
+		// Other rate limits on sending an email, e.g. the per-address
+		// cooldown, also match the sentinel. The server's message says how
+		// long to wait.
+		err := handleErrorResponse(resp)
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && !errors.Is(err, ErrEmailSendLimitExceeded) {
+			apiErr.sentinels = append(apiErr.sentinels, ErrEmailSendLimitExceeded)
+		}
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, handleErrorResponse(resp)
+	}
+
+	var res types.ResendResponse
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return nil, newResponseDecodingError(err)
+	}
+
+	return &res, nil
+}
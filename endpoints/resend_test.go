@@ -0,0 +1,73 @@
+package endpoints_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrehanabbasi/supabase-auth-go/endpoints"
+	"github.com/mrehanabbasi/supabase-auth-go/types"
+)
+
+func TestResend(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	ctx := context.Background()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("redirect_to") {
+		case "http://localhost:3000/email":
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"code":429,"error_code":"over_email_send_rate_limit","msg":"email rate limit exceeded"}`))
+		case "http://localhost:3000/cooldown":
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"code":429,"error_code":"over_request_rate_limit","msg":"For security purposes, you can only request this after 60 seconds."}`))
+		case "":
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"code":429,"error_code":"over_sms_send_rate_limit","msg":"For security purposes, you can only request this after 42 seconds."}`))
+		default:
+			_, _ = w.Write([]byte(`{}`))
+		}
+	}))
+	defer srv.Close()
+
+	client := endpoints.New("", "api_key").WithCustomAuthURL(srv.URL)
+
+	_, err := client.Resend(ctx, types.ResendRequest{
+		Type:            types.ResendTypeSignup,
+		Email:           "user@test.com",
+		EmailRedirectTo: "http://localhost:3000/welcome",
+	})
+	require.NoError(err)
+
+	_, err = client.Resend(ctx, types.ResendRequest{
+		Type:            types.ResendTypeSignup,
+		Email:           "user@test.com",
+		EmailRedirectTo: "http://localhost:3000/email",
+	})
+	assert.ErrorIs(err, endpoints.ErrEmailSendLimitExceeded)
+
+	// Other rate limits on emails match the same error, keeping the server's
+	// message.
+	_, err = client.Resend(ctx, types.ResendRequest{
+		Type:            types.ResendTypeSignup,
+		Email:           "user@test.com",
+		EmailRedirectTo: "http://localhost:3000/cooldown",
+	})
+	assert.ErrorIs(err, endpoints.ErrEmailSendLimitExceeded)
+	assert.ErrorContains(err, "60 seconds")
+
+	// SMS rate limits are not email send limits, but are still rate limits.
+	_, err = client.Resend(ctx, types.ResendRequest{
+		Type:  types.ResendTypeSMS,
+		Phone: "+15555550100",
+	})
+	assert.NotErrorIs(err, endpoints.ErrEmailSendLimitExceeded)
+	assert.ErrorIs(err, endpoints.ErrSMSSendLimitExceeded)
+	assert.True(endpoints.IsRateLimited(err))
+	assert.ErrorContains(err, "42 seconds")
+}
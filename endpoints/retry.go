@@ -0,0 +1,191 @@
+package endpoints
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	backoff "github.com/cenkalti/backoff/v4"
+
+	"github.com/mrehanabbasi/supabase-auth-go/types"
+)
+
+const (
+	defaultRetryInitialInterval     = 500 * time.Millisecond
+	defaultRetryMaxInterval         = 10 * time.Second
+	defaultRetryMultiplier          = 2
+	defaultRetryRandomizationFactor = 0.5
+
+	// maxDrain is how much of a response body is read before it is
+	// discarded for a retry, so that the connection can be reused.
+	maxDrain = 4 << 10
+)
+
+// retry sends r with client, retrying according to the client's retry
+// policy.
+func (c *Client) retry(client *http.Client, r *http.Request) (*http.Response, error) {
+	policy := c.retryPolicy
+	if policy.MaxAttempts <= 1 {
+		return client.Do(r)
+	}
+
+	ctx := r.Context()
+	b := newBackOff(policy)
+	idempotent := isIdempotent(r)
+	rewindable := r.Body == nil || r.Body == http.NoBody || r.GetBody != nil
+
+	for attempt := 1; ; attempt++ {
+		req := r
+		if attempt > 1 && r.Body != nil && r.Body != http.NoBody {
+			body, err := r.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = r.Clone(ctx)
+			req.Body = body
+		}
+
+		// Track whether any of the request was written, as a request that
+		// failed before then cannot have reached the Auth server.
+		var sent atomic.Bool
+		req = req.WithContext(httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+			WroteHeaderField: func(string, []string) { sent.Store(true) },
+		}))
+
+		resp, err := client.Do(req)
+		if attempt >= policy.MaxAttempts || !rewindable {
+			return resp, err
+		}
+
+		wait := b.NextBackOff()
+		retry := false
+		if err != nil {
+			retry = ctx.Err() == nil && (idempotent || policy.RetryNonIdempotent || !sent.Load())
+		} else if isRetryableStatus(resp.StatusCode) {
+			retry = idempotent || policy.RetryNonIdempotent
+			if after, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+				if after > maxRetryAfter(policy) {
+					retry = false
+				}
+				wait = after
+			}
+		}
+		if !retry {
+			return resp, err
+		}
+
+		if resp != nil {
+			_, _ = io.CopyN(io.Discard, resp.Body, maxDrain)
+			resp.Body.Close()
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func newBackOff(policy types.RetryPolicy) *backoff.ExponentialBackOff {
+	b := backoff.NewExponentialBackOff()
+	b.InitialInterval = defaultRetryInitialInterval
+	b.MaxInterval = defaultRetryMaxInterval
+	b.Multiplier = defaultRetryMultiplier
+	b.RandomizationFactor = defaultRetryRandomizationFactor
+	b.MaxElapsedTime = 0
+
+	if policy.InitialInterval > 0 {
+		b.InitialInterval = policy.InitialInterval
+	}
+	if policy.MaxInterval > 0 {
+		b.MaxInterval = policy.MaxInterval
+	}
+	if policy.Multiplier > 0 {
+		b.Multiplier = policy.Multiplier
+	}
+	if policy.RandomizationFactor > 0 {
+		b.RandomizationFactor = policy.RandomizationFactor
+	} else if policy.RandomizationFactor < 0 {
+		b.RandomizationFactor = 0
+	}
+	b.Reset()
+	return b
+}
+
+func maxRetryAfter(policy types.RetryPolicy) time.Duration {
+	if policy.MaxRetryAfter > 0 {
+		return policy.MaxRetryAfter
+	}
+	if policy.MaxInterval > 0 {
+		return policy.MaxInterval
+	}
+	return defaultRetryMaxInterval
+}
+
+type idempotentKey struct{}
+
+// Idempotent marks the calls made with ctx as safe to send more than once,
+// so that the client's retry policy retries them like GET requests. Use it
+// for PUT and DELETE calls whose side effects are harmless to repeat. Some
+// are not: UpdateUser, for one, may send an email change or reauthentication
+// email.
+func Idempotent(ctx context.Context) context.Context {
+	return context.WithValue(ctx, idempotentKey{}, true)
+}
+
+// isIdempotent reports whether r can safely be sent more than once. Only
+// requests that cannot change anything are by default, as PUT and DELETE
+// endpoints of the Auth server may send emails.
+func isIdempotent(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	if idempotent, _ := r.Context().Value(idempotentKey{}).(bool); idempotent {
+		return true
+	}
+	return r.Header.Get("Idempotency-Key") != "" || r.Header.Get("X-Idempotency-Key") != ""
+}
+
+func isRetryableStatus(code int) bool {
+	switch code {
+	case http.StatusRequestTimeout,
+		http.StatusTooManyRequests,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// parseRetryAfter parses a Retry-After header, given either in seconds or as
+// an HTTP date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0), true
+	}
+	return 0, false
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
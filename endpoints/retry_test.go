@@ -0,0 +1,140 @@
+package endpoints_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrehanabbasi/supabase-auth-go/endpoints"
+	"github.com/mrehanabbasi/supabase-auth-go/types"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+var fastRetries = types.RetryPolicy{
+	MaxAttempts:         3,
+	InitialInterval:     time.Millisecond,
+	RandomizationFactor: -1,
+}
+
+func TestRetryPolicy(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	ctx := context.Background()
+
+	var calls atomic.Int32
+	var failures atomic.Int32
+	var retryAfter atomic.Value
+	retryAfter.Store("0")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if r.Method == http.MethodPost {
+			body, _ := io.ReadAll(r.Body)
+			assert.NotEmpty(body)
+		}
+		if failures.Add(-1) >= 0 {
+			w.Header().Set("Retry-After", retryAfter.Load().(string))
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"code":503,"error_code":"unexpected_failure","msg":"unavailable"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	client := endpoints.New("", "api_key").WithCustomAuthURL(srv.URL)
+	retrying := client.WithRetryPolicy(fastRetries)
+
+	// Retries are disabled by default.
+	failures.Store(1)
+	_, err := client.GetSettings(ctx)
+	assert.Error(err)
+	assert.EqualValues(1, calls.Swap(0))
+
+	// Idempotent requests are retried.
+	failures.Store(2)
+	_, err = retrying.GetSettings(ctx)
+	require.NoError(err)
+	assert.EqualValues(3, calls.Swap(0))
+
+	// Up to MaxAttempts.
+	failures.Store(5)
+	_, err = retrying.GetSettings(ctx)
+	assert.Error(err)
+	assert.EqualValues(3, calls.Swap(0))
+
+	// A Retry-After longer than MaxRetryAfter is not waited for.
+	retryAfter.Store("120")
+	failures.Store(1)
+	_, err = retrying.GetSettings(ctx)
+	assert.Error(err)
+	assert.EqualValues(1, calls.Swap(0))
+	retryAfter.Store("0")
+
+	// Requests that are not idempotent are not retried on error responses...
+	failures.Store(1)
+	_, err = retrying.SignInWithEmailPassword(ctx, "user@test.com", "password")
+	assert.Error(err)
+	assert.EqualValues(1, calls.Swap(0))
+
+	// ...including PUT and DELETE, which may send emails...
+	failures.Store(1)
+	_, err = retrying.WithToken("token").UpdateUser(ctx, types.UpdateUserRequest{})
+	assert.Error(err)
+	assert.EqualValues(1, calls.Swap(0))
+
+	// ...unless the call is marked as idempotent...
+	failures.Store(1)
+	_, err = retrying.WithToken("token").UpdateUser(endpoints.Idempotent(ctx), types.UpdateUserRequest{})
+	require.NoError(err)
+	assert.EqualValues(2, calls.Swap(0))
+
+	// ...or the policy allows it, in which case the body is sent again.
+	policy := fastRetries
+	policy.RetryNonIdempotent = true
+	failures.Store(1)
+	_, err = client.WithRetryPolicy(policy).SignInWithEmailPassword(ctx, "user@test.com", "password")
+	require.NoError(err)
+	assert.EqualValues(2, calls.Swap(0))
+}
+
+func TestRetryPolicyConnectionErrors(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	var attempts atomic.Int32
+	refused := &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		attempts.Add(1)
+		return nil, &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	})}
+	client := endpoints.New("", "api_key").
+		WithCustomAuthURL("http://auth.test").
+		WithClient(refused).
+		WithRetryPolicy(fastRetries)
+
+	// A request that never reached the server is retried, even if it is not
+	// idempotent.
+	_, err := client.SignInWithEmailPassword(ctx, "user@test.com", "password")
+	assert.Error(err)
+	assert.EqualValues(3, attempts.Swap(0))
+
+	// Cancelling the context stops retrying.
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = client.GetSettings(cancelled)
+	assert.Error(err)
+	assert.EqualValues(1, attempts.Load())
+}
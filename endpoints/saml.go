@@ -19,12 +19,12 @@ const (
 // If successful, the server returns an XML response. Making sense of this is
 // outside the scope of this client, so it is simply returned as []byte.
 func (c *Client) SAMLMetadata(ctx context.Context) ([]byte, error) {
-	r, err := c.newRequest(ctx, samlMetadataPath, http.MethodGet, nil)
+	r, err := c.newRequest(ctx, "SAMLMetadata", samlMetadataPath, http.MethodGet, nil)
 	if err != nil {
 		return nil, newRequestCreationError(err)
 	}
 
-	resp, err := c.client.Do(r)
+	resp, err := c.do(r)
 	if err != nil {
 		return nil, newRequestDispatchError(err)
 	}
@@ -67,5 +67,6 @@ func (c *Client) SAMLACS(req *http.Request) (*http.Response, error) {
 		return nil, err
 	}
 	req.URL = u
-	return c.client.Do(req)
+	req = req.WithContext(context.WithValue(req.Context(), endpointKey{}, "SAMLACS"))
+	return c.do(req)
 }
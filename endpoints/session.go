@@ -0,0 +1,29 @@
+package endpoints
+
+import (
+	"context"
+
+	"github.com/mrehanabbasi/supabase-auth-go/types"
+)
+
+// saveSession persists s into the session store, if one is configured.
+func (c *Client) saveSession(ctx context.Context, s types.Session) error {
+	if c.sessionStore == nil {
+		return nil
+	}
+	if err := c.sessionStore.Save(ctx, c.sessionKey, s); err != nil {
+		return newSessionStoreError(err)
+	}
+	return nil
+}
+
+// deleteSession removes the stored session, if a session store is configured.
+func (c *Client) deleteSession(ctx context.Context) error {
+	if c.sessionStore == nil {
+		return nil
+	}
+	if err := c.sessionStore.Delete(ctx, c.sessionKey); err != nil {
+		return newSessionStoreError(err)
+	}
+	return nil
+}
@@ -14,12 +14,12 @@ var settingsPath = "/settings"
 //
 // Returns the publicly available settings for this auth instance.
 func (c *Client) GetSettings(ctx context.Context) (*types.SettingsResponse, error) {
-	r, err := c.newRequest(ctx, settingsPath, http.MethodGet, nil)
+	r, err := c.newRequest(ctx, "GetSettings", settingsPath, http.MethodGet, nil)
 	if err != nil {
 		return nil, newRequestCreationError(err)
 	}
 
-	resp, err := c.client.Do(r)
+	resp, err := c.do(r)
 	if err != nil {
 		return nil, newRequestDispatchError(err)
 	}
@@ -16,18 +16,41 @@ const signupPath = "/signup"
 // POST /signup
 //
 // Register a new user with an email and password.
+//
+// If FlowType is pkce, the confirmation link carries an auth code instead of
+// tokens, and the response contains the verifier to exchange it with.
 func (c *Client) Signup(ctx context.Context, req types.SignupRequest) (*types.SignupResponse, error) {
+	if req.Password != "" {
+		if err := c.ValidatePassword(ctx, req.Password); err != nil {
+			return nil, err
+		}
+	}
+
+	challenge, verifier, err := newPKCEChallenge(req.FlowType)
+	if err != nil {
+		return nil, err
+	}
+	state, redirectTo, err := c.storeVerifier(ctx, verifier, req.RedirectTo)
+	if err != nil {
+		return nil, err
+	}
+
 	body := new(bytes.Buffer)
-	if err := json.NewEncoder(body).Encode(req); err != nil {
+	payload := struct {
+		types.SignupRequest
+		pkceChallenge
+	}{req, challenge}
+	if err := json.NewEncoder(body).Encode(payload); err != nil {
 		return nil, newRequestEncodingError(err)
 	}
 
-	r, err := c.newRequest(ctx, signupPath, http.MethodPost, body)
+	r, err := c.newRequest(ctx, "Signup", signupPath, http.MethodPost, body)
 	if err != nil {
 		return nil, newRequestCreationError(err)
 	}
+	addRedirectTo(r, redirectTo)
 
-	resp, err := c.client.Do(r)
+	resp, err := c.do(r)
 	if err != nil {
 		return nil, newRequestDispatchError(err)
 	}
@@ -51,6 +74,8 @@ func (c *Client) Signup(ctx context.Context, req types.SignupRequest) (*types.Si
 	if res.Session.User.ID != uuid.Nil {
 		res.User = res.Session.User
 	}
+	res.Verifier = verifier
+	res.State = state
 
 	return &res, nil
 }
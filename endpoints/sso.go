@@ -21,18 +21,35 @@ const ssoPath = "/sso"
 // Auth allows you to skip following the redirect by setting SkipHTTPRedirect
 // on the request struct. In this case, the URL to redirect to will be returned
 // in the response.
+//
+// If FlowType is pkce, the user is redirected back with an auth code instead
+// of tokens, and the response contains the verifier to exchange it with.
 func (c *Client) SSO(ctx context.Context, req types.SSORequest) (*types.SSOResponse, error) {
+	challenge, verifier, err := newPKCEChallenge(req.FlowType)
+	if err != nil {
+		return nil, err
+	}
+	state, redirectTo, err := c.storeVerifier(ctx, verifier, req.RedirectTo)
+	if err != nil {
+		return nil, err
+	}
+	req.RedirectTo = redirectTo
+
 	body := new(bytes.Buffer)
-	if err := json.NewEncoder(body).Encode(req); err != nil {
+	payload := struct {
+		types.SSORequest
+		pkceChallenge
+	}{req, challenge}
+	if err := json.NewEncoder(body).Encode(payload); err != nil {
 		return nil, newRequestEncodingError(err)
 	}
 
-	r, err := c.newRequest(ctx, http.MethodPost, ssoPath, body)
+	r, err := c.newRequest(ctx, "SSO", ssoPath, http.MethodPost, body)
 	if err != nil {
 		return nil, newRequestCreationError(err)
 	}
 
-	resp, err := c.client.Do(r)
+	resp, err := c.do(r)
 	if err != nil {
 		return nil, newRequestDispatchError(err)
 	}
@@ -42,6 +59,8 @@ func (c *Client) SSO(ctx context.Context, req types.SSORequest) (*types.SSORespo
 		// directly.
 		return &types.SSOResponse{
 			HTTPResponse: resp,
+			Verifier:     verifier,
+			State:        state,
 		}, nil
 	}
 	defer resp.Body.Close()
@@ -57,5 +76,7 @@ func (c *Client) SSO(ctx context.Context, req types.SSORequest) (*types.SSORespo
 	if err != nil {
 		return nil, newResponseDecodingError(err)
 	}
+	res.Verifier = verifier
+	res.State = state
 	return &res, nil
 }
@@ -43,6 +43,29 @@ func (c *Client) RefreshToken(ctx context.Context, refreshToken string) (*types.
 	})
 }
 
+// Exchange an auth code for a session
+//
+// This is a convenience method that calls Token with the pkce grant type. Use
+// it to complete flows started with FlowType set to pkce, passing the auth
+// code the user was redirected back with and the verifier returned when the
+// flow was started, or its state if the client has a VerifierStore.
+func (c *Client) ExchangeCodeForSession(ctx context.Context, req types.ExchangeCodeForSessionRequest) (*types.TokenResponse, error) {
+	verifier := req.CodeVerifier
+	if verifier == "" && req.State != "" {
+		v, err := c.takeVerifier(ctx, req.State)
+		if err != nil {
+			return nil, err
+		}
+		verifier = v
+	}
+
+	return c.Token(ctx, types.TokenRequest{
+		GrantType:    "pkce",
+		Code:         req.AuthCode,
+		CodeVerifier: verifier,
+	})
+}
+
 // Sign in with id token
 //
 // This is a convenience method that calls Token with the id_token grant type
@@ -103,12 +126,12 @@ func (c *Client) Token(ctx context.Context, req types.TokenRequest) (*types.Toke
 		return nil, newRequestEncodingError(err)
 	}
 
-	r, err := c.newRequest(ctx, tokenPath+"?grant_type="+req.GrantType, http.MethodPost, body)
+	r, err := c.newRequest(ctx, "Token", tokenPath+"?grant_type="+req.GrantType, http.MethodPost, body)
 	if err != nil {
 		return nil, newRequestCreationError(err)
 	}
 
-	resp, err := c.client.Do(r)
+	resp, err := c.do(r)
 	if err != nil {
 		return nil, newRequestDispatchError(err)
 	}
@@ -120,7 +143,17 @@ func (c *Client) Token(ctx context.Context, req types.TokenRequest) (*types.Toke
 
 	var res types.TokenResponse
 	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
-		return nil, err
+		return nil, newResponseDecodingError(err)
+	}
+
+	if req.GrantType == "refresh_token" {
+		c.emit(types.AuthChangeEventTokenRefreshed, &res.Session)
+	} else {
+		c.emit(types.AuthChangeEventSignedIn, &res.Session)
+	}
+
+	if err := c.saveSession(ctx, res.Session); err != nil {
+		return &res, err
 	}
 
 	return &res, nil
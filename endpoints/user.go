@@ -15,12 +15,12 @@ var userPath = "/user"
 //
 // Get the JSON object for the logged in user (requires authentication)
 func (c *Client) GetUser(ctx context.Context) (*types.UserResponse, error) {
-	r, err := c.newRequest(ctx, userPath, http.MethodGet, nil)
+	r, err := c.newRequest(ctx, "GetUser", userPath, http.MethodGet, nil)
 	if err != nil {
 		return nil, newRequestCreationError(err)
 	}
 
-	resp, err := c.client.Do(r)
+	resp, err := c.do(r)
 	if err != nil {
 		return nil, newRequestDispatchError(err)
 	}
@@ -44,18 +44,41 @@ func (c *Client) GetUser(ctx context.Context) (*types.UserResponse, error) {
 // Update a user (Requires authentication). Apart from changing email/password,
 // this method can be used to set custom user data. Changing the email will
 // result in a magiclink being sent out.
+//
+// If FlowType is pkce, the email change link carries an auth code instead of
+// tokens, and the response contains the verifier to exchange it with.
 func (c *Client) UpdateUser(ctx context.Context, req types.UpdateUserRequest) (*types.UpdateUserResponse, error) {
+	if req.Password != nil {
+		if err := c.ValidatePassword(ctx, *req.Password); err != nil {
+			return nil, err
+		}
+	}
+
+	challenge, verifier, err := newPKCEChallenge(req.FlowType)
+	if err != nil {
+		return nil, err
+	}
+	state, redirectTo, err := c.storeVerifier(ctx, verifier, req.RedirectTo)
+	if err != nil {
+		return nil, err
+	}
+
 	body := new(bytes.Buffer)
-	if err := json.NewEncoder(body).Encode(req); err != nil {
+	payload := struct {
+		types.UpdateUserRequest
+		pkceChallenge
+	}{req, challenge}
+	if err := json.NewEncoder(body).Encode(payload); err != nil {
 		return nil, newRequestEncodingError(err)
 	}
 
-	r, err := c.newRequest(ctx, userPath, http.MethodPut, body)
+	r, err := c.newRequest(ctx, "UpdateUser", userPath, http.MethodPut, body)
 	if err != nil {
 		return nil, newRequestCreationError(err)
 	}
+	addRedirectTo(r, redirectTo)
 
-	resp, err := c.client.Do(r)
+	resp, err := c.do(r)
 	if err != nil {
 		return nil, newRequestDispatchError(err)
 	}
@@ -70,6 +93,10 @@ func (c *Client) UpdateUser(ctx context.Context, req types.UpdateUserRequest) (*
 	if err != nil {
 		return nil, newResponseDecodingError(err)
 	}
+	res.Verifier = verifier
+	res.State = state
+
+	c.emitUser(types.AuthChangeEventUserUpdated, res.User)
 
 	return &res, nil
 }
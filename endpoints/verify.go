@@ -26,6 +26,9 @@ const verifyPath = "/verify"
 // NOTE: This endpoint may return a nil error, but the Response can contain
 // error details extracted from the returned URL. Please check that the Error,
 // ErrorCode and/or ErrorDescription fields of the response are empty.
+//
+// When the redirect carries a session and OnAuthStateChange has subscribers,
+// the user is fetched with GetUser so the event can include it.
 func (c *Client) Verify(ctx context.Context, req types.VerifyRequest) (*types.VerifyResponse, error) {
 	if req.Type == "" {
 		return nil, types.ErrInvalidVerifyRequest
@@ -37,7 +40,7 @@ func (c *Client) Verify(ctx context.Context, req types.VerifyRequest) (*types.Ve
 		return nil, types.ErrInvalidVerifyRequest
 	}
 
-	r, err := c.newRequest(ctx, verifyPath, http.MethodGet, nil)
+	r, err := c.newRequest(ctx, "Verify", verifyPath, http.MethodGet, nil)
 	if err != nil {
 		return nil, newRequestCreationError(err)
 	}
@@ -51,7 +54,7 @@ func (c *Client) Verify(ctx context.Context, req types.VerifyRequest) (*types.Ve
 	// Set up a client that will not follow the redirect.
 	noRedirClient := noRedirClient(c.client)
 
-	resp, err := noRedirClient.Do(r)
+	resp, err := c.doWith(&noRedirClient, r)
 	if err != nil {
 		return nil, newRequestDispatchError(err)
 	}
@@ -80,7 +83,7 @@ func (c *Client) Verify(ctx context.Context, req types.VerifyRequest) (*types.Ve
 		expiresIn, _ = strconv.Atoi(expiry)
 	}
 
-	return &types.VerifyResponse{
+	res := &types.VerifyResponse{
 		URL: redirURL,
 
 		AccessToken:  values.Get("access_token"),
@@ -92,7 +95,23 @@ func (c *Client) Verify(ctx context.Context, req types.VerifyRequest) (*types.Ve
 		Error:            values.Get("error"),
 		ErrorCode:        values.Get("error_code"),
 		ErrorDescription: values.Get("error_description"),
-	}, nil
+	}
+
+	// The redirect carries no user, so it is fetched for the event. If that
+	// fails, no event is emitted rather than one without a user.
+	if res.AccessToken != "" && c.events.active() {
+		if user, err := c.WithToken(res.AccessToken).GetUser(ctx); err == nil {
+			c.emitVerified(req.Type, &types.Session{
+				AccessToken:  res.AccessToken,
+				RefreshToken: res.RefreshToken,
+				TokenType:    res.TokenType,
+				ExpiresIn:    res.ExpiresIn,
+				User:         user.User,
+			})
+		}
+	}
+
+	return res, nil
 }
 
 // POST /verify
@@ -101,20 +120,20 @@ func (c *Client) Verify(ctx context.Context, req types.VerifyRequest) (*types.Ve
 // or magiclink or invite and the token is a token returned from either /signup
 // or /recover or /magiclink.
 //
-// This differs from GET /verify as it requires an email or phone to be given,
-// which is used to verify the token associated to the user. It also returns a
-// JSON response rather than a redirect.
+// This differs from GET /verify as it requires either a token hash, or an
+// email or phone that is used to verify the token associated to the user. It
+// also returns a JSON response rather than a redirect.
 func (c *Client) VerifyForUser(ctx context.Context, req types.VerifyForUserRequest) (*types.VerifyForUserResponse, error) {
 	if req.Type == "" {
 		return nil, types.ErrInvalidVerifyRequest
 	}
-	if req.Token == "" {
+	if (req.Token == "") == (req.TokenHash == "") {
 		return nil, types.ErrInvalidVerifyRequest
 	}
-	if req.RedirectTo == "" {
+	if req.Token != "" && req.Email == "" && req.Phone == "" {
 		return nil, types.ErrInvalidVerifyRequest
 	}
-	if req.Email == "" && req.Phone == "" {
+	if req.TokenHash != "" && (req.Email != "" || req.Phone != "") {
 		return nil, types.ErrInvalidVerifyRequest
 	}
 
@@ -123,12 +142,12 @@ func (c *Client) VerifyForUser(ctx context.Context, req types.VerifyForUserReque
 		return nil, newRequestEncodingError(err)
 	}
 
-	r, err := c.newRequest(ctx, verifyPath, http.MethodPost, body)
+	r, err := c.newRequest(ctx, "VerifyForUser", verifyPath, http.MethodPost, body)
 	if err != nil {
 		return nil, newRequestCreationError(err)
 	}
 
-	resp, err := c.client.Do(r)
+	resp, err := c.do(r)
 	if err != nil {
 		return nil, newRequestDispatchError(err)
 	}
@@ -144,5 +163,11 @@ func (c *Client) VerifyForUser(ctx context.Context, req types.VerifyForUserReque
 		return nil, newResponseDecodingError(err)
 	}
 
+	c.emitVerified(req.Type, &res.Session)
+
+	if err := c.saveSession(ctx, res.Session); err != nil {
+		return &res, err
+	}
+
 	return &res, nil
 }
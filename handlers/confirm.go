@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	auth "github.com/mrehanabbasi/supabase-auth-go"
+	"github.com/mrehanabbasi/supabase-auth-go/endpoints"
+	"github.com/mrehanabbasi/supabase-auth-go/types"
+)
+
+const (
+	ErrorCodeMissingToken       = "missing_token"
+	ErrorCodeVerificationFailed = "verification_failed"
+)
+
+type ConfirmConfig struct {
+	// Client is used to verify the token.
+	Client auth.Client
+
+	// RedirectURL is where the user is sent after verifying, if the link has
+	// no "next" query parameter or it is not allowed. Defaults to "/".
+	RedirectURL string
+	// AllowedRedirects lists the absolute URLs outside the site that "next"
+	// may point to, e.g. "https://app.example.com/account". A URL is allowed
+	// if it has the same scheme and host as an entry, and its path is within
+	// the entry's path. Paths on the same site, such as "/account", are
+	// always allowed.
+	AllowedRedirects []string
+
+	// Sessions returns the store the session is saved into under SessionKey
+	// once the token is verified. If SessionKey is empty,
+	// types.DefaultSessionKey is used.
+	Sessions   func(w http.ResponseWriter, r *http.Request) types.SessionStore
+	SessionKey string
+
+	// Error renders errors. Use TemplateError to render them with an HTML
+	// template. Defaults to writing the error description as plain text.
+	Error ErrorFunc
+}
+
+// Confirm returns a handler for the links sent in confirmation, magic link,
+// invite, recovery and email change emails, when the email templates link to
+// your own site, e.g.
+//
+//	{{ .SiteURL }}/auth/confirm?token_hash={{ .TokenHash }}&type=email&next=/account
+//
+// It verifies the token_hash with VerifyForUser, saves the session and
+// redirects to next, or to cfg.RedirectURL if next is missing or is not
+// allowed by cfg.AllowedRedirects.
+//
+// It panics if cfg.Client or cfg.Sessions is not set.
+func Confirm(cfg ConfirmConfig) http.Handler {
+	if cfg.Client == nil || cfg.Sessions == nil {
+		panic("handlers: Client and Sessions must be set")
+	}
+	if cfg.RedirectURL == "" {
+		cfg.RedirectURL = "/"
+	}
+	if cfg.Error == nil {
+		cfg.Error = defaultError
+	}
+	key := cfg.SessionKey
+	if key == "" {
+		key = types.DefaultSessionKey
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		tokenHash := q.Get("token_hash")
+		verificationType := q.Get("type")
+		if tokenHash == "" || verificationType == "" {
+			cfg.Error(w, r, &Error{
+				Status:      http.StatusBadRequest,
+				Code:        ErrorCodeMissingToken,
+				Description: "The link is incomplete, please check you copied all of it",
+			})
+			return
+		}
+
+		client := cfg.Client.WithSessionStore(cfg.Sessions(w, r), key)
+		_, err := client.VerifyForUser(r.Context(), types.VerifyForUserRequest{
+			Type:      types.VerificationType(verificationType),
+			TokenHash: tokenHash,
+		})
+		if err != nil {
+			cfg.Error(w, r, verificationError(err))
+			return
+		}
+
+		next, ok := safeRedirect(q.Get("next"), cfg.AllowedRedirects)
+		if !ok {
+			next = cfg.RedirectURL
+		}
+		http.Redirect(w, r, next, http.StatusSeeOther)
+	})
+}
+
+func verificationError(err error) *Error {
+	var apiErr *endpoints.APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode < http.StatusInternalServerError {
+		code := ErrorCodeVerificationFailed
+		if apiErr.ErrorCode != "" {
+			code = string(apiErr.ErrorCode)
+		}
+		return &Error{
+			Status:      http.StatusBadRequest,
+			Code:        code,
+			Description: "The link is invalid or has expired",
+			Err:         err,
+		}
+	}
+
+	return &Error{
+		Status:      http.StatusBadGateway,
+		Code:        ErrorCodeServerError,
+		Description: "Failed to verify the link",
+		Err:         err,
+	}
+}
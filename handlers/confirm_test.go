@@ -0,0 +1,101 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	auth "github.com/mrehanabbasi/supabase-auth-go"
+	"github.com/mrehanabbasi/supabase-auth-go/handlers"
+	"github.com/mrehanabbasi/supabase-auth-go/session"
+	"github.com/mrehanabbasi/supabase-auth-go/types"
+)
+
+func TestConfirm(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body["token_hash"] != "hash" || body["type"] != "email" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"code":403,"error_code":"otp_expired","msg":"Email link is invalid or has expired"}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(types.Session{AccessToken: "access", RefreshToken: "refresh"})
+	}))
+	defer srv.Close()
+
+	sessions := session.NewMemoryStore()
+	var rendered *handlers.Error
+	h := handlers.Confirm(handlers.ConfirmConfig{
+		Client:           auth.NewWithCustomAuthURL(auth.Config{BaseURL: srv.URL, APIKey: "api_key"}),
+		RedirectURL:      "/welcome",
+		AllowedRedirects: []string{"https://app.example.com/account"},
+		Sessions: func(http.ResponseWriter, *http.Request) types.SessionStore {
+			return sessions
+		},
+		Error: func(w http.ResponseWriter, _ *http.Request, e *handlers.Error) {
+			rendered = e
+			w.WriteHeader(e.Status)
+		},
+	})
+
+	confirm := func(next string) *httptest.ResponseRecorder {
+		q := url.Values{"token_hash": {"hash"}, "type": {"email"}}
+		if next != "" {
+			q.Set("next", next)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/auth/confirm?"+q.Encode(), nil))
+		return w
+	}
+
+	w := confirm("/account/settings?tab=email")
+	require.Equal(http.StatusSeeOther, w.Code)
+	assert.Equal("/account/settings?tab=email", w.Header().Get("Location"))
+
+	saved, err := sessions.Load(context.Background(), types.DefaultSessionKey)
+	require.NoError(err)
+	assert.Equal("access", saved.AccessToken)
+
+	redirects := map[string]string{
+		"":                                "/welcome",
+		"https://app.example.com/account": "https://app.example.com/account",
+		"https://app.example.com/account/billing":  "https://app.example.com/account/billing",
+		"https://app.example.com/accounting":       "/welcome",
+		"https://app.example.com/admin":            "/welcome",
+		"http://app.example.com/account":           "/welcome",
+		"https://evil.example.com/account":         "/welcome",
+		"https://app.example.com@evil.com/account": "/welcome",
+		"//evil.com":          "/welcome",
+		"/\\evil.com":         "/welcome",
+		"javascript:alert(1)": "/welcome",
+		"account":             "/welcome",
+	}
+	for next, want := range redirects {
+		w := confirm(next)
+		require.Equal(http.StatusSeeOther, w.Code, next)
+		assert.Equal(want, w.Header().Get("Location"), next)
+	}
+
+	// Missing token.
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/auth/confirm?type=email", nil))
+	assert.Equal(http.StatusBadRequest, w.Code)
+	require.NotNil(rendered)
+	assert.Equal(handlers.ErrorCodeMissingToken, rendered.Code)
+
+	// Expired token.
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/auth/confirm?token_hash=old&type=email", nil))
+	assert.Equal(http.StatusBadRequest, w.Code)
+	assert.Equal("otp_expired", rendered.Code)
+}
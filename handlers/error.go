@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"html/template"
+	"net/http"
+)
+
+const (
+	ErrorCodeInvalidProvider = "invalid_provider"
+	ErrorCodeInvalidState    = "invalid_state"
+	ErrorCodeMissingCode     = "missing_code"
+	ErrorCodeServerError     = "server_error"
+)
+
+// Error describes why a handler could not complete a sign in. It is passed to
+// the configured ErrorFunc to be rendered.
+type Error struct {
+	// Status is the HTTP status code the response should have.
+	Status int
+	// Code is one of the ErrorCode constants, or the error code the Auth
+	// server redirected back with, e.g. "access_denied".
+	Code string
+	// Description is a human readable description that is safe to show to
+	// the user.
+	Description string
+	// Err is the underlying error, if any. It may contain details that should
+	// not be shown to the user.
+	Err error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Code + ": " + e.Description + ": " + e.Err.Error()
+	}
+	return e.Code + ": " + e.Description
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// ErrorFunc renders an error page for a failed sign in.
+type ErrorFunc func(w http.ResponseWriter, r *http.Request, e *Error)
+
+// TemplateError returns an ErrorFunc that executes t with the *Error as its
+// data, and e.Status as the status code.
+func TemplateError(t *template.Template) ErrorFunc {
+	return func(w http.ResponseWriter, _ *http.Request, e *Error) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(e.Status)
+		_ = t.Execute(w, e)
+	}
+}
+
+// defaultError writes e.Description as plain text.
+func defaultError(w http.ResponseWriter, _ *http.Request, e *Error) {
+	http.Error(w, e.Description, e.Status)
+}
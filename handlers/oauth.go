@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+
+	auth "github.com/mrehanabbasi/supabase-auth-go"
+	"github.com/mrehanabbasi/supabase-auth-go/types"
+)
+
+// fragmentParam marks a callback request whose URL fragment has already been
+// moved into the query string, so that the fragment page is served only once.
+const fragmentParam = "from_fragment"
+
+type OAuthConfig struct {
+	// Client is used to start the flow and exchange the code.
+	Client auth.Client
+	// Providers lists the providers users may sign in with. The start handler
+	// reads the provider from the "provider" query parameter, which may be
+	// omitted if there is only one.
+	Providers []types.Provider
+	// Scopes are optional additional scopes to request from the provider.
+	Scopes string
+
+	// CallbackURL is the absolute URL the callback handler is served at. It
+	// must be in the Auth server's redirect allow list.
+	CallbackURL string
+	// RedirectURL is where the user is sent after signing in. Defaults to "/".
+	RedirectURL string
+
+	// Verifiers returns the store that keeps the PKCE verifier between the
+	// start and callback handlers. Use the Bind method of a pkce.CookieStore,
+	// which also ties the flow to the browser that started it, or of a
+	// pkce.MemoryStore.
+	Verifiers func(w http.ResponseWriter, r *http.Request) types.VerifierStore
+	// Sessions returns the store the session is saved into under SessionKey
+	// once the user has signed in, such as the Bind method of a
+	// session.CookieStore. If SessionKey is empty, types.DefaultSessionKey is
+	// used.
+	Sessions   func(w http.ResponseWriter, r *http.Request) types.SessionStore
+	SessionKey string
+
+	// Error renders errors. Use TemplateError to render them with an HTML
+	// template. Defaults to writing the error description as plain text.
+	Error ErrorFunc
+}
+
+func (cfg OAuthConfig) withDefaults() OAuthConfig {
+	if cfg.Client == nil || cfg.Verifiers == nil || cfg.Sessions == nil {
+		panic("handlers: Client, Verifiers and Sessions must be set")
+	}
+	if cfg.RedirectURL == "" {
+		cfg.RedirectURL = "/"
+	}
+	if cfg.Error == nil {
+		cfg.Error = defaultError
+	}
+	return cfg
+}
+
+// OAuthStart returns a handler that starts signing in with an external OAuth
+// provider. It calls Authorize with PKCE, saves the verifier, and redirects
+// the browser to the provider.
+//
+// It panics if cfg.Client, cfg.Verifiers or cfg.Sessions is not set.
+func OAuthStart(cfg OAuthConfig) http.Handler {
+	cfg = cfg.withDefaults()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		provider := types.Provider(r.URL.Query().Get("provider"))
+		if provider == "" && len(cfg.Providers) == 1 {
+			provider = cfg.Providers[0]
+		}
+		if !slices.Contains(cfg.Providers, provider) {
+			cfg.Error(w, r, &Error{
+				Status:      http.StatusBadRequest,
+				Code:        ErrorCodeInvalidProvider,
+				Description: "Unsupported sign in provider",
+			})
+			return
+		}
+
+		client := cfg.Client.WithVerifierStore(cfg.Verifiers(w, r))
+		res, err := client.Authorize(r.Context(), types.AuthorizeRequest{
+			Provider:   provider,
+			RedirectTo: cfg.CallbackURL,
+			FlowType:   types.FlowPKCE,
+			Scopes:     cfg.Scopes,
+		})
+		if err != nil {
+			cfg.Error(w, r, &Error{
+				Status:      http.StatusBadGateway,
+				Code:        ErrorCodeServerError,
+				Description: "Failed to start sign in",
+				Err:         err,
+			})
+			return
+		}
+
+		http.Redirect(w, r, res.AuthorizationURL, http.StatusFound)
+	})
+}
+
+// OAuthCallback returns a handler for the URL the Auth server redirects back
+// to, i.e. cfg.CallbackURL. It validates the state, exchanges the auth code
+// for a session, saves the session and redirects to cfg.RedirectURL.
+//
+// If the Auth server redirects back with an error, it is rendered with
+// cfg.Error. Errors in the URL fragment are moved into the query string by a
+// small script, since browsers do not send the fragment to the server.
+//
+// It panics if cfg.Client, cfg.Verifiers or cfg.Sessions is not set.
+func OAuthCallback(cfg OAuthConfig) http.Handler {
+	cfg = cfg.withDefaults()
+	key := cfg.SessionKey
+	if key == "" {
+		key = types.DefaultSessionKey
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		q := r.URL.Query()
+		state := q.Get(types.StateParam)
+		verifiers := cfg.Verifiers(w, r)
+
+		if q.Get("error") != "" {
+			// The flow is over, so the verifier is no longer needed.
+			if state != "" {
+				_ = verifiers.Delete(ctx, state)
+			}
+			code := q.Get("error_code")
+			if code == "" {
+				code = q.Get("error")
+			}
+			cfg.Error(w, r, &Error{
+				Status:      http.StatusUnauthorized,
+				Code:        code,
+				Description: q.Get("error_description"),
+			})
+			return
+		}
+
+		authCode := q.Get("code")
+		if authCode == "" {
+			if q.Get(fragmentParam) == "" {
+				serveFragmentPage(w)
+				return
+			}
+			cfg.Error(w, r, &Error{
+				Status:      http.StatusBadRequest,
+				Code:        ErrorCodeMissingCode,
+				Description: "No auth code was received",
+			})
+			return
+		}
+
+		if state == "" {
+			cfg.Error(w, r, &Error{
+				Status:      http.StatusBadRequest,
+				Code:        ErrorCodeInvalidState,
+				Description: "Sign in could not be verified, please try again",
+			})
+			return
+		}
+
+		client := cfg.Client.
+			WithVerifierStore(verifiers).
+			WithSessionStore(cfg.Sessions(w, r), key)
+		_, err := client.ExchangeCodeForSession(ctx, types.ExchangeCodeForSessionRequest{
+			AuthCode: authCode,
+			State:    state,
+		})
+		if errors.Is(err, types.ErrVerifierNotFound) {
+			cfg.Error(w, r, &Error{
+				Status:      http.StatusBadRequest,
+				Code:        ErrorCodeInvalidState,
+				Description: "Sign in could not be verified, please try again",
+				Err:         err,
+			})
+			return
+		}
+		if err != nil {
+			cfg.Error(w, r, &Error{
+				Status:      http.StatusBadGateway,
+				Code:        ErrorCodeServerError,
+				Description: "Failed to complete sign in",
+				Err:         err,
+			})
+			return
+		}
+
+		http.Redirect(w, r, cfg.RedirectURL, http.StatusSeeOther)
+	})
+}
+
+// fragmentPage reloads the callback with the URL fragment appended to the
+// query string, so that errors the Auth server puts in the fragment reach the
+// handler. Fragments without an error are dropped, so that tokens are never
+// moved into the query string.
+const fragmentPage = `<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>Signing in</title></head><body>
+<script>
+var s = location.search ? location.search + "&" : "?";
+var f = location.hash.substring(1);
+var h = /(^|&)error=/.test(f) ? f + "&" : "";
+location.replace(location.pathname + s + h + "` + fragmentParam + `=1");
+</script>
+<noscript>Sign in failed: no auth code was received.</noscript>
+</body></html>
+`
+
+func serveFragmentPage(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	_, _ = w.Write([]byte(fragmentPage))
+}
@@ -0,0 +1,181 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	auth "github.com/mrehanabbasi/supabase-auth-go"
+	"github.com/mrehanabbasi/supabase-auth-go/handlers"
+	"github.com/mrehanabbasi/supabase-auth-go/pkce"
+	"github.com/mrehanabbasi/supabase-auth-go/session"
+	"github.com/mrehanabbasi/supabase-auth-go/types"
+)
+
+// authServer is a fake Auth server that implements the PKCE OAuth flow.
+type authServer struct {
+	*httptest.Server
+
+	challenge  string
+	redirectTo string
+}
+
+func newAuthServer(t *testing.T) *authServer {
+	as := &authServer{}
+	as.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/authorize":
+			as.challenge = r.URL.Query().Get("code_challenge")
+			as.redirectTo = r.URL.Query().Get("redirect_to")
+			w.Header().Set("Location", "https://github.com/login/oauth/authorize?client_id=abc")
+			w.WriteHeader(http.StatusFound)
+		case "/token":
+			var body map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			sha := sha256.Sum256([]byte(body["code_verifier"]))
+			if body["auth_code"] != "code" || base64.RawURLEncoding.EncodeToString(sha[:]) != as.challenge {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"code":400,"error_code":"bad_code_verifier","msg":"invalid code"}`))
+				return
+			}
+			_ = json.NewEncoder(w).Encode(types.Session{AccessToken: "access", RefreshToken: "refresh"})
+		}
+	}))
+	t.Cleanup(as.Close)
+	return as
+}
+
+func TestOAuth(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	as := newAuthServer(t)
+	verifiers, err := pkce.NewCookieStore(bytes.Repeat([]byte{1}, 32), pkce.CookieOptions{})
+	require.NoError(err)
+	sessions := session.NewMemoryStore()
+
+	cfg := handlers.OAuthConfig{
+		Client:      auth.NewWithCustomAuthURL(auth.Config{BaseURL: as.URL, APIKey: "api_key"}),
+		Providers:   []types.Provider{types.ProviderGitHub},
+		CallbackURL: "http://localhost:3000/auth/callback",
+		RedirectURL: "/dashboard",
+		Verifiers:   verifiers.Bind,
+		Sessions: func(http.ResponseWriter, *http.Request) types.SessionStore {
+			return sessions
+		},
+		Error: handlers.TemplateError(template.Must(template.New("error").Parse(`error: {{.Code}}`))),
+	}
+	start := handlers.OAuthStart(cfg)
+	callback := handlers.OAuthCallback(cfg)
+
+	// Unknown provider.
+	w := httptest.NewRecorder()
+	start.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/auth/login?provider=apple", nil))
+	assert.Equal(http.StatusBadRequest, w.Code)
+	assert.Equal("error: invalid_provider", w.Body.String())
+
+	// Start the flow.
+	w = httptest.NewRecorder()
+	start.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/auth/login", nil))
+	require.Equal(http.StatusFound, w.Code)
+	assert.Contains(w.Header().Get("Location"), "github.com/login/oauth/authorize")
+	cookies := w.Result().Cookies()
+	require.Len(cookies, 1)
+	require.NotEmpty(as.challenge)
+
+	u, err := url.Parse(as.redirectTo)
+	require.NoError(err)
+	state := u.Query().Get(types.StateParam)
+	require.NotEmpty(state)
+
+	// The callback from another browser has no verifier cookie.
+	w = httptest.NewRecorder()
+	callback.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/auth/callback?code=code&state="+state, nil))
+	assert.Equal(http.StatusBadRequest, w.Code)
+	assert.Equal("error: invalid_state", w.Body.String())
+
+	// Complete the flow.
+	r := httptest.NewRequest(http.MethodGet, "/auth/callback?code=code&state="+state, nil)
+	r.AddCookie(cookies[0])
+	w = httptest.NewRecorder()
+	callback.ServeHTTP(w, r)
+	require.Equal(http.StatusSeeOther, w.Code)
+	assert.Equal("/dashboard", w.Header().Get("Location"))
+
+	saved, err := sessions.Load(context.Background(), types.DefaultSessionKey)
+	require.NoError(err)
+	assert.Equal("access", saved.AccessToken)
+
+	// The verifier cookie is cleared.
+	cookies = w.Result().Cookies()
+	require.Len(cookies, 1)
+	assert.Negative(cookies[0].MaxAge)
+}
+
+func TestOAuthCallbackErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	as := newAuthServer(t)
+	verifiers := pkce.NewMemoryStore(0)
+	callback := handlers.OAuthCallback(handlers.OAuthConfig{
+		Client:    auth.NewWithCustomAuthURL(auth.Config{BaseURL: as.URL, APIKey: "api_key"}),
+		Providers: []types.Provider{types.ProviderGitHub},
+		Verifiers: verifiers.Bind,
+		Sessions: func(http.ResponseWriter, *http.Request) types.SessionStore {
+			return session.NewMemoryStore()
+		},
+		Error: handlers.TemplateError(template.Must(template.New("error").Parse(`{{.Code}}: {{.Description}}`))),
+	})
+
+	// The Auth server redirected back with an error.
+	w := httptest.NewRecorder()
+	callback.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/callback?error=access_denied&error_description=User+denied+access", nil))
+	assert.Equal(http.StatusUnauthorized, w.Code)
+	assert.Equal("access_denied: User denied access", w.Body.String())
+
+	// Errors in the fragment are moved to the query string by the browser.
+	w = httptest.NewRecorder()
+	callback.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/callback", nil))
+	assert.Equal(http.StatusOK, w.Code)
+	assert.Contains(w.Body.String(), "location.replace")
+
+	w = httptest.NewRecorder()
+	callback.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/callback?from_fragment=1", nil))
+	assert.Equal(http.StatusBadRequest, w.Code)
+	assert.Equal("missing_code: No auth code was received", w.Body.String())
+
+	// Missing state.
+	w = httptest.NewRecorder()
+	callback.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/callback?code=code", nil))
+	assert.Equal(http.StatusBadRequest, w.Code)
+	assert.Contains(w.Body.String(), handlers.ErrorCodeInvalidState)
+
+	// A code can only be exchanged once.
+	ctx := context.Background()
+	client := auth.NewWithCustomAuthURL(auth.Config{BaseURL: as.URL, APIKey: "api_key"})
+	res, err := client.WithVerifierStore(verifiers).Authorize(ctx, types.AuthorizeRequest{
+		Provider: types.ProviderGitHub,
+		FlowType: types.FlowPKCE,
+	})
+	assert.NoError(err)
+
+	w = httptest.NewRecorder()
+	callback.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/callback?code=code&state="+res.State, nil))
+	assert.Equal(http.StatusSeeOther, w.Code)
+	assert.Equal("/", w.Header().Get("Location"))
+
+	w = httptest.NewRecorder()
+	callback.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/callback?code=code&state="+res.State, nil))
+	assert.Equal(http.StatusBadRequest, w.Code)
+	assert.Contains(w.Body.String(), handlers.ErrorCodeInvalidState)
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"net/url"
+	"strings"
+)
+
+// safeRedirect returns next if it is safe to redirect to, i.e. it is a path
+// on the same site, or it is within one of allowed.
+//
+// Entries of allowed are absolute URLs. next matches an entry if it has the
+// same scheme and host, and its path is within the entry's path.
+func safeRedirect(next string, allowed []string) (string, bool) {
+	if next == "" || strings.ContainsAny(next, "\\\x00\r\n\t") {
+		return "", false
+	}
+
+	u, err := url.Parse(next)
+	if err != nil || u.User != nil || u.Opaque != "" {
+		return "", false
+	}
+
+	// A path on the same site. "//host" is a protocol relative URL, so it
+	// must not be treated as a path.
+	if u.Scheme == "" && u.Host == "" {
+		if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") {
+			return "", false
+		}
+		return next, true
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", false
+	}
+	for _, a := range allowed {
+		au, err := url.Parse(a)
+		if err != nil || au.Host == "" {
+			continue
+		}
+		if !strings.EqualFold(u.Scheme, au.Scheme) || !strings.EqualFold(u.Host, au.Host) {
+			continue
+		}
+		if withinPath(u.EscapedPath(), au.EscapedPath()) {
+			return next, true
+		}
+	}
+	return "", false
+}
+
+func withinPath(path, prefix string) bool {
+	prefix = strings.TrimSuffix(prefix, "/")
+	if prefix == "" {
+		return true
+	}
+	return path == prefix || strings.HasPrefix(path, prefix+"/")
+}
@@ -0,0 +1,66 @@
+package integration_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrehanabbasi/supabase-auth-go/types"
+)
+
+func TestSignInAnonymously(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	// Anonymous sign-ins disabled
+	_, err := client.SignInAnonymously(ctx, types.SignInAnonymouslyRequest{})
+	assert.Error(err)
+
+	client := autoconfirmClient
+
+	session, err := client.SignInAnonymously(ctx, types.SignInAnonymouslyRequest{
+		Data: map[string]interface{}{
+			"cart": "abc",
+		},
+	})
+	require.NoError(err)
+	assert.NotEmpty(session.AccessToken)
+	assert.NotEmpty(session.RefreshToken)
+	assert.True(session.User.IsAnonymous)
+	assert.Empty(session.User.Email)
+	assert.Equal("abc", session.User.UserMetadata["cart"])
+
+	authedClient := client.WithToken(session.AccessToken)
+
+	// Invalid requests
+	_, err = authedClient.ConvertAnonymousUser(ctx, types.ConvertAnonymousUserRequest{})
+	assert.ErrorIs(err, types.ErrInvalidConvertAnonymousRequest)
+	_, err = authedClient.ConvertAnonymousUser(ctx, types.ConvertAnonymousUserRequest{
+		Email: randomEmail(),
+		Phone: randomPhoneNumber(),
+	})
+	assert.ErrorIs(err, types.ErrInvalidConvertAnonymousRequest)
+
+	// Convert to a permanent user
+	email := randomEmail()
+	user, err := authedClient.ConvertAnonymousUser(ctx, types.ConvertAnonymousUserRequest{
+		Email:    email,
+		Password: "password",
+	})
+	require.NoError(err)
+	assert.Equal(session.User.ID, user.ID)
+	assert.Equal(email, user.Email)
+	assert.False(user.IsAnonymous)
+
+	// Can no longer be converted
+	_, err = authedClient.ConvertAnonymousUser(ctx, types.ConvertAnonymousUserRequest{
+		Email: randomEmail(),
+	})
+	assert.ErrorIs(err, types.ErrUserNotAnonymous)
+
+	// Sign in with the new credentials
+	token, err := client.SignInWithEmailPassword(ctx, email, "password")
+	require.NoError(err)
+	assert.Equal(session.User.ID, token.User.ID)
+}
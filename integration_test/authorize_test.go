@@ -6,6 +6,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/mrehanabbasi/supabase-auth-go/pkce"
 	"github.com/mrehanabbasi/supabase-auth-go/types"
 )
 
@@ -35,6 +36,16 @@ func TestAuthorize(t *testing.T) {
 	require.NotEmpty(resp.AuthorizationURL)
 	require.NotEmpty(resp.Verifier)
 
+	// Test login with PKCE and a verifier store
+	resp, err = autoconfirmClient.WithVerifierStore(pkce.NewMemoryStore(0)).Authorize(ctx, types.AuthorizeRequest{
+		Provider:   "github",
+		FlowType:   "pkce",
+		RedirectTo: "http://localhost:3000/callback",
+	})
+	require.NoError(err)
+	require.NotEmpty(resp.Verifier)
+	require.NotEmpty(resp.State)
+
 	// No provider chosen
 	_, err = autoconfirmClient.Authorize(ctx, types.AuthorizeRequest{})
 	assert.Error(err)
@@ -0,0 +1,68 @@
+package integration_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrehanabbasi/supabase-auth-go/endpoints"
+	"github.com/mrehanabbasi/supabase-auth-go/types"
+)
+
+func TestIdentities(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	client := autoconfirmClient
+
+	session, err := client.Signup(ctx, types.SignupRequest{
+		Email:    randomEmail(),
+		Password: "password",
+	})
+	require.NoError(err)
+	authedClient := client.WithToken(session.AccessToken)
+
+	// The email identity is created on signup
+	identities, err := authedClient.GetUserIdentities(ctx)
+	require.NoError(err)
+	require.Len(identities.Identities, 1)
+	assert.Equal("email", identities.Identities[0].Provider)
+	assert.NotEqual(uuid.Nil, identities.Identities[0].IdentityID)
+
+	// Link an identity
+	resp, err := authedClient.LinkIdentity(ctx, types.LinkIdentityRequest{
+		Provider: "github",
+	})
+	require.NoError(err)
+	assert.Contains(resp.AuthorizationURL, "github.com/login/oauth/authorize")
+	assert.Empty(resp.Verifier)
+
+	resp, err = authedClient.LinkIdentity(ctx, types.LinkIdentityRequest{
+		Provider: "github",
+		FlowType: "pkce",
+	})
+	require.NoError(err)
+	assert.NotEmpty(resp.AuthorizationURL)
+	assert.NotEmpty(resp.Verifier)
+
+	// Requires authentication
+	_, err = client.LinkIdentity(ctx, types.LinkIdentityRequest{
+		Provider: "github",
+	})
+	assert.Error(err)
+
+	// Invalid requests
+	err = authedClient.UnlinkIdentity(ctx, types.UnlinkIdentityRequest{})
+	assert.ErrorIs(err, types.ErrInvalidUnlinkIdentityRequest)
+	err = authedClient.UnlinkIdentity(ctx, types.UnlinkIdentityRequest{
+		IdentityID: uuid.New(),
+	})
+	assert.ErrorIs(err, endpoints.ErrIdentityNotFound)
+
+	// The only identity cannot be unlinked
+	err = authedClient.UnlinkIdentity(ctx, types.UnlinkIdentityRequest{
+		IdentityID: identities.Identities[0].IdentityID,
+	})
+	assert.ErrorIs(err, endpoints.ErrSingleIdentityNotDeletable)
+}
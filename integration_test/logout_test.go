@@ -36,3 +36,45 @@ func TestLogout(t *testing.T) {
 	_, err = client.RefreshToken(ctx, session.RefreshToken)
 	assert.Error(err)
 }
+
+func TestLogoutWithScope(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	client := autoconfirmClient
+
+	// Invalid scope.
+	err := client.LogoutWithScope(ctx, types.LogoutRequest{Scope: "everywhere"})
+	assert.ErrorIs(err, types.ErrInvalidLogoutRequest)
+
+	// Create a user signed in on two devices.
+	email := randomEmail()
+	password := randomString(10)
+	_, err = client.Signup(ctx, types.SignupRequest{
+		Email:    email,
+		Password: password,
+	})
+	require.NoError(err)
+	current, err := client.SignInWithEmailPassword(ctx, email, password)
+	require.NoError(err)
+	other, err := client.SignInWithEmailPassword(ctx, email, password)
+	require.NoError(err)
+
+	// Sign out of all other devices.
+	err = client.WithToken(current.AccessToken).LogoutWithScope(ctx, types.LogoutRequest{
+		Scope: types.LogoutScopeOthers,
+	})
+	require.NoError(err)
+	_, err = client.RefreshToken(ctx, other.RefreshToken)
+	assert.Error(err)
+	current, err = client.RefreshToken(ctx, current.RefreshToken)
+	require.NoError(err)
+
+	// Sign out of this device.
+	err = client.WithToken(current.AccessToken).LogoutWithScope(ctx, types.LogoutRequest{
+		Scope: types.LogoutScopeLocal,
+	})
+	require.NoError(err)
+	_, err = client.RefreshToken(ctx, current.RefreshToken)
+	assert.Error(err)
+}
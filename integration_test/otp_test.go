@@ -26,6 +26,15 @@ func TestOTP(t *testing.T) {
 	})
 	assert.NoError(err)
 
+	// OTP with PKCE
+	resp, err := client.OTPWithPKCE(ctx, types.OTPRequest{
+		Email:      randomEmail(),
+		CreateUser: true,
+		FlowType:   types.FlowPKCE,
+	})
+	assert.NoError(err)
+	assert.NotEmpty(resp.Verifier)
+
 	// Create user with SMS OTP, but SMS disabled
 	phone := randomPhoneNumber()
 	err = client.OTP(ctx, types.OTPRequest{
@@ -19,4 +19,12 @@ func TestRecover(t *testing.T) {
 
 	err = client.Recover(ctx, types.RecoverRequest{})
 	assert.Error(err)
+
+	// Recover with PKCE
+	resp, err := client.RecoverWithPKCE(ctx, types.RecoverRequest{
+		Email:    randomEmail(),
+		FlowType: types.FlowPKCE,
+	})
+	assert.NoError(err)
+	assert.NotEmpty(resp.Verifier)
 }
@@ -0,0 +1,46 @@
+package integration_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrehanabbasi/supabase-auth-go/types"
+)
+
+func TestResend(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	// Invalid requests
+	var invalidErr *types.ErrInvalidResendRequest
+	_, err := client.Resend(ctx, types.ResendRequest{
+		Email: randomEmail(),
+	})
+	assert.ErrorAs(err, &invalidErr)
+	_, err = client.Resend(ctx, types.ResendRequest{
+		Type: types.ResendTypeSignup,
+	})
+	assert.ErrorAs(err, &invalidErr)
+	_, err = client.Resend(ctx, types.ResendRequest{
+		Type:  types.ResendTypeSMS,
+		Email: randomEmail(),
+	})
+	assert.ErrorAs(err, &invalidErr)
+
+	// Resend signup confirmation
+	email := randomEmail()
+	_, err = client.Signup(ctx, types.SignupRequest{
+		Email:    email,
+		Password: "password",
+	})
+	require.NoError(err)
+
+	_, err = client.Resend(ctx, types.ResendRequest{
+		Type:            types.ResendTypeSignup,
+		Email:           email,
+		EmailRedirectTo: "http://localhost:3000/welcome",
+	})
+	require.NoError(err)
+}
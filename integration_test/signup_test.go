@@ -41,6 +41,15 @@ func TestSignup(t *testing.T) {
 	assert.InDelta(time.Now().Unix(), dupeUserResp.UpdatedAt.Unix(), float64(time.Second))
 	assert.Equal(userResp.ID, dupeUserResp.ID)
 
+	// Signup with PKCE
+	pkceResp, err := client.Signup(ctx, types.SignupRequest{
+		Email:    randomEmail(),
+		Password: "password",
+		FlowType: types.FlowPKCE,
+	})
+	require.NoError(err)
+	assert.NotEmpty(pkceResp.Verifier)
+
 	// Sign up with phone
 	// Will error because SMS is not configured on the test server.
 	user, err := client.Signup(ctx, types.SignupRequest{
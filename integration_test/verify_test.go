@@ -63,4 +63,32 @@ func TestVerify(t *testing.T) {
 	// Test VerifyForUser, invalid request
 	_, err = client.VerifyForUser(ctx, types.VerifyForUserRequest{})
 	assert.Error(err)
+	_, err = client.VerifyForUser(ctx, types.VerifyForUserRequest{
+		Type:      types.VerificationTypeSignup,
+		TokenHash: "abcde",
+		Email:     email,
+	})
+	assert.ErrorIs(err, types.ErrInvalidVerifyRequest)
+
+	// Test VerifyForUser with a token hash
+	link, err := withAdmin(client).AdminGenerateLink(ctx, types.AdminGenerateLinkRequest{
+		Type:  types.LinkTypeMagicLink,
+		Email: email,
+	})
+	require.NoError(err)
+
+	session, err := client.VerifyForUser(ctx, types.VerifyForUserRequest{
+		Type:      types.VerificationTypeMagiclink,
+		TokenHash: link.HashedToken,
+	})
+	require.NoError(err)
+	assert.NotEmpty(session.AccessToken)
+	assert.Equal(email, session.User.Email)
+
+	// The token hash can only be used once
+	_, err = client.VerifyForUser(ctx, types.VerifyForUserRequest{
+		Type:      types.VerificationTypeMagiclink,
+		TokenHash: link.HashedToken,
+	})
+	assert.Error(err)
 }
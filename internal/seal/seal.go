@@ -0,0 +1,101 @@
+package seal
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"io"
+	"strings"
+)
+
+// MinSecretLength is the minimum length of the secret passed to New.
+const MinSecretLength = 32
+
+var (
+	ErrInvalidSecret = errors.New("secret must be at least 32 bytes")
+	ErrInvalidValue  = errors.New("value is malformed or was not sealed with this secret")
+)
+
+// Sealer encrypts values with AES-256-GCM, then signs them with HMAC-SHA256,
+// so that they can be handed to a client, e.g. in a cookie, and later trusted
+// again without keeping any server state. Both keys are derived from a single
+// secret.
+type Sealer struct {
+	aead   cipher.AEAD
+	macKey []byte
+}
+
+func New(secret []byte) (*Sealer, error) {
+	if len(secret) < MinSecretLength {
+		return nil, ErrInvalidSecret
+	}
+
+	block, err := aes.NewCipher(derive(secret, "encryption"))
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Sealer{
+		aead:   aead,
+		macKey: derive(secret, "signing"),
+	}, nil
+}
+
+// Seal encrypts and signs plaintext. name binds the result to its use, e.g.
+// a cookie name, so that a value sealed for one name cannot be opened as
+// another. The result only contains URL safe characters.
+func (s *Sealer) Seal(name string, plaintext []byte) (string, error) {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+
+	payload := base64.RawURLEncoding.EncodeToString(s.aead.Seal(nonce, nonce, plaintext, []byte(name)))
+	return payload + "." + base64.RawURLEncoding.EncodeToString(s.sign(name, payload)), nil
+}
+
+// Open verifies and decrypts a value returned by Seal with the same name. It
+// returns ErrInvalidValue if the value was tampered with.
+func (s *Sealer) Open(name string, value string) ([]byte, error) {
+	payload, sig, ok := strings.Cut(value, ".")
+	if !ok {
+		return nil, ErrInvalidValue
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(mac, s.sign(name, payload)) {
+		return nil, ErrInvalidValue
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil || len(data) < s.aead.NonceSize() {
+		return nil, ErrInvalidValue
+	}
+	nonce, ciphertext := data[:s.aead.NonceSize()], data[s.aead.NonceSize():]
+	plaintext, err := s.aead.Open(nil, nonce, ciphertext, []byte(name))
+	if err != nil {
+		return nil, ErrInvalidValue
+	}
+	return plaintext, nil
+}
+
+func (s *Sealer) sign(name string, payload string) []byte {
+	h := hmac.New(sha256.New, s.macKey)
+	h.Write([]byte(name))
+	h.Write([]byte{0})
+	h.Write([]byte(payload))
+	return h.Sum(nil)
+}
+
+func derive(secret []byte, purpose string) []byte {
+	h := hmac.New(sha256.New, secret)
+	h.Write([]byte("supabase-auth-go " + purpose))
+	return h.Sum(nil)
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/mrehanabbasi/supabase-auth-go/policy"
+)
+
+// Authorize wraps next so that it is only called for authenticated requests
+// whose claims satisfy p. Unauthenticated requests receive a 401 JSON error,
+// and requests rejected by p receive a 403 JSON error whose reason field
+// describes the failure.
+func (m *Middleware) Authorize(p policy.Policy, next http.Handler) http.Handler {
+	return m.AuthorizeFunc(func(*http.Request) policy.Policy { return p }, next)
+}
+
+// AuthorizeFunc is like Authorize, but builds the policy for each request.
+// Use it for policies that depend on the request, for example:
+//
+//	m.AuthorizeFunc(func(r *http.Request) policy.Policy {
+//		return policy.RequireClaim("org_id", r.PathValue("org"))
+//	}, next)
+func (m *Middleware) AuthorizeFunc(fn func(r *http.Request) policy.Policy, next http.Handler) http.Handler {
+	return m.RequireAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, _ := ClaimsFromContext(r.Context())
+		if err := fn(r)(claims); err != nil {
+			WriteError(w, forbidden(err))
+			return
+		}
+		next.ServeHTTP(w, r)
+	}))
+}
+
+func forbidden(err error) Error {
+	var pErr *policy.Error
+	if !errors.As(err, &pErr) {
+		pErr = &policy.Error{
+			Reason:  policy.ReasonClaimMismatch,
+			Message: err.Error(),
+		}
+	}
+	return Error{
+		Code:      http.StatusForbidden,
+		ErrorCode: string(pErr.Reason),
+		Message:   pErr.Message,
+		Reason:    pErr,
+	}
+}
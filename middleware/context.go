@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/mrehanabbasi/supabase-auth-go/types"
+)
+
+type contextKey int
+
+const (
+	tokenKey contextKey = iota
+	claimsKey
+	userKey
+)
+
+// TokenFromContext returns the access token of the authenticated request.
+func TokenFromContext(ctx context.Context) (string, bool) {
+	token, ok := ctx.Value(tokenKey).(string)
+	return token, ok
+}
+
+// ClaimsFromContext returns the claims of the authenticated request's access
+// token.
+func ClaimsFromContext(ctx context.Context) (*types.Claims, bool) {
+	claims, ok := ctx.Value(claimsKey).(*types.Claims)
+	return claims, ok
+}
+
+// UserFromContext returns the user of the authenticated request. It is only
+// set when the token was checked with Client.GetUser rather than verified
+// locally.
+func UserFromContext(ctx context.Context) (*types.User, bool) {
+	user, ok := ctx.Value(userKey).(*types.User)
+	return user, ok
+}
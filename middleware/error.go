@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/mrehanabbasi/supabase-auth-go/endpoints"
+	"github.com/mrehanabbasi/supabase-auth-go/policy"
+)
+
+const (
+	errCodeNoAuthorization   = string(endpoints.ErrorCodeNoAuthorization)
+	errCodeBadJWT            = string(endpoints.ErrorCodeBadJWT)
+	errCodeUnexpectedFailure = string(endpoints.ErrorCodeUnexpectedFailure)
+)
+
+// Error is the JSON body written when a request is rejected. It has the same
+// shape as the errors returned by the Auth server.
+type Error struct {
+	Code      int    `json:"code"`
+	ErrorCode string `json:"error_code"`
+	Message   string `json:"msg"`
+
+	// Reason is set when a request is rejected by a policy.
+	Reason *policy.Error `json:"reason,omitempty"`
+}
+
+// WriteError writes e as a JSON response with e.Code as the status code.
+func WriteError(w http.ResponseWriter, e Error) {
+	if e.Code == http.StatusUnauthorized {
+		w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(e.Code)
+	_ = json.NewEncoder(w).Encode(e)
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+)
+
+// TokenExtractor returns the access token carried by a request, or an empty
+// string if there is none.
+type TokenExtractor func(r *http.Request) string
+
+// FromAuthorizationHeader extracts the token from an
+// "Authorization: Bearer <token>" header. This is the default extractor.
+func FromAuthorizationHeader() TokenExtractor {
+	return func(r *http.Request) string {
+		scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+		if !ok || !strings.EqualFold(scheme, "Bearer") {
+			return ""
+		}
+		return strings.TrimSpace(token)
+	}
+}
+
+// FromHeader extracts the token from the raw value of the named header.
+func FromHeader(name string) TokenExtractor {
+	return func(r *http.Request) string {
+		return r.Header.Get(name)
+	}
+}
+
+// FromCookie extracts the token from the named cookie.
+func FromCookie(name string) TokenExtractor {
+	return func(r *http.Request) string {
+		c, err := r.Cookie(name)
+		if err != nil {
+			return ""
+		}
+		return c.Value
+	}
+}
+
+// FromQuery extracts the token from the named query parameter.
+//
+// Tokens in URLs end up in logs and browser history, so prefer a header or
+// cookie where possible. This is mostly useful for WebSocket upgrades.
+func FromQuery(param string) TokenExtractor {
+	return func(r *http.Request) string {
+		return r.URL.Query().Get(param)
+	}
+}
+
+// FirstOf tries each extractor in order and returns the first token found.
+func FirstOf(extractors ...TokenExtractor) TokenExtractor {
+	return func(r *http.Request) string {
+		for _, extract := range extractors {
+			if token := extract(r); token != "" {
+				return token
+			}
+		}
+		return ""
+	}
+}
@@ -0,0 +1,184 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	jwt "github.com/golang-jwt/jwt/v4"
+
+	auth "github.com/mrehanabbasi/supabase-auth-go"
+	"github.com/mrehanabbasi/supabase-auth-go/endpoints"
+	"github.com/mrehanabbasi/supabase-auth-go/types"
+	"github.com/mrehanabbasi/supabase-auth-go/verifier"
+)
+
+type Config struct {
+	// Verifier, if set, is used to verify tokens locally.
+	Verifier verifier.Verifier
+	// Client, if set, is used to check tokens with GetUser when no Verifier is
+	// set, or when the Verifier cannot decide, e.g. because the token is signed
+	// with a key it does not know.
+	//
+	// At least one of Verifier and Client must be set.
+	Client auth.Client
+	// Extractor finds the token in the request. Defaults to
+	// FromAuthorizationHeader.
+	Extractor TokenExtractor
+	// Logger, if set, logs why tokens were rejected. The response only
+	// carries a fixed message, so as not to reveal the verifier's errors.
+	Logger *slog.Logger
+}
+
+// Middleware authenticates requests using the access token they carry, and
+// stores the result in the request context. Use ClaimsFromContext,
+// UserFromContext and TokenFromContext to read it in your handlers.
+type Middleware struct {
+	cfg Config
+}
+
+// Set up a new authentication middleware.
+//
+// It panics if neither cfg.Verifier nor cfg.Client is set.
+func New(cfg Config) *Middleware {
+	if cfg.Verifier == nil && cfg.Client == nil {
+		panic("middleware: Verifier or Client must be set")
+	}
+	if cfg.Extractor == nil {
+		cfg.Extractor = FromAuthorizationHeader()
+	}
+	return &Middleware{cfg: cfg}
+}
+
+// RequireAuth wraps next so that it is only called for requests with a valid
+// access token. Other requests receive a 401 JSON error.
+func (m *Middleware) RequireAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, e := m.authenticate(r)
+		if e != nil {
+			WriteError(w, *e)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// OptionalAuth wraps next so that requests without an access token are passed
+// through unauthenticated. Requests with an invalid token still receive a 401
+// JSON error.
+func (m *Middleware) OptionalAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if m.cfg.Extractor(r) == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		ctx, e := m.authenticate(r)
+		if e != nil {
+			WriteError(w, *e)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func (m *Middleware) authenticate(r *http.Request) (context.Context, *Error) {
+	ctx := r.Context()
+
+	token := m.cfg.Extractor(r)
+	if token == "" {
+		return nil, &Error{
+			Code:      http.StatusUnauthorized,
+			ErrorCode: errCodeNoAuthorization,
+			Message:   "This endpoint requires a Bearer token",
+		}
+	}
+
+	if m.cfg.Verifier != nil {
+		claims, err := m.cfg.Verifier.Verify(ctx, token)
+		if err == nil {
+			ctx = context.WithValue(ctx, tokenKey, token)
+			return context.WithValue(ctx, claimsKey, claims), nil
+		}
+		if m.cfg.Client == nil || isDefinitive(err) {
+			return nil, m.badJWT(ctx, err)
+		}
+	}
+
+	res, err := m.cfg.Client.WithToken(token).GetUser(ctx)
+	if err != nil {
+		if isUnauthorized(err) {
+			return nil, m.badJWT(ctx, err)
+		}
+		m.log(ctx, slog.LevelError, "failed to authenticate request", err)
+		return nil, &Error{
+			Code:      http.StatusInternalServerError,
+			ErrorCode: errCodeUnexpectedFailure,
+			Message:   "Failed to authenticate request",
+		}
+	}
+
+	// The Auth server has accepted the token, so its claims can be trusted
+	// without verifying the signature again.
+	var claims types.Claims
+	if _, _, err := jwt.NewParser().ParseUnverified(token, &claims); err != nil {
+		return nil, m.badJWT(ctx, err)
+	}
+
+	ctx = context.WithValue(ctx, tokenKey, token)
+	ctx = context.WithValue(ctx, claimsKey, &claims)
+	return context.WithValue(ctx, userKey, &res.User), nil
+}
+
+// badJWT logs why a token was rejected, and returns the error to respond
+// with.
+func (m *Middleware) badJWT(ctx context.Context, err error) *Error {
+	m.log(ctx, slog.LevelInfo, "rejected access token", err)
+
+	msg := "invalid JWT"
+	if errors.Is(err, verifier.ErrTokenExpired) {
+		msg = "invalid JWT: token is expired"
+	}
+	return &Error{
+		Code:      http.StatusUnauthorized,
+		ErrorCode: errCodeBadJWT,
+		Message:   msg,
+	}
+}
+
+func (m *Middleware) log(ctx context.Context, level slog.Level, msg string, err error) {
+	if m.cfg.Logger == nil {
+		return
+	}
+	m.cfg.Logger.LogAttrs(ctx, level, msg, slog.String("error", err.Error()))
+}
+
+// isDefinitive reports whether a verifier error proves the token is invalid,
+// as opposed to the verifier being unable to check it. Tokens signed with an
+// algorithm, key type or key the verifier does not have, e.g. HS256 tokens
+// under a JWKS verifier, are left to the Auth server.
+func isDefinitive(err error) bool {
+	return errors.Is(err, verifier.ErrTokenExpired) ||
+		errors.Is(err, verifier.ErrTokenNotValidYet) ||
+		errors.Is(err, verifier.ErrInvalidAudience) ||
+		errors.Is(err, verifier.ErrInvalidSignature) ||
+		errors.Is(err, verifier.ErrMalformedToken)
+}
+
+// isUnauthorized reports whether a GetUser error means the Auth server
+// rejected the token.
+func isUnauthorized(err error) bool {
+	if errors.Is(err, endpoints.ErrInvalidJWT) ||
+		errors.Is(err, endpoints.ErrSessionNotFound) ||
+		errors.Is(err, endpoints.ErrNoAuthorization) {
+		return true
+	}
+
+	var apiErr *endpoints.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusUnauthorized ||
+			apiErr.StatusCode == http.StatusForbidden ||
+			apiErr.StatusCode == http.StatusNotFound
+	}
+	return false
+}
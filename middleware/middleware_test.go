@@ -0,0 +1,275 @@
+package middleware_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	auth "github.com/mrehanabbasi/supabase-auth-go"
+	"github.com/mrehanabbasi/supabase-auth-go/middleware"
+	"github.com/mrehanabbasi/supabase-auth-go/policy"
+	"github.com/mrehanabbasi/supabase-auth-go/types"
+	"github.com/mrehanabbasi/supabase-auth-go/verifier"
+)
+
+const jwtSecret = "secret"
+
+func token(t *testing.T, expiresIn time.Duration, appMetadata map[string]interface{}) string {
+	s, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":          "8d7b1b4c-1f0e-4a52-8d3c-3a1e5c2f6b90",
+		"aud":          "authenticated",
+		"exp":          time.Now().Add(expiresIn).Unix(),
+		"role":         "authenticated",
+		"email":        "user@test.com",
+		"aal":          "aal1",
+		"app_metadata": appMetadata,
+	}).SignedString([]byte(jwtSecret))
+	require.NoError(t, err)
+	return s
+}
+
+// echo responds with the authenticated subject, or "anonymous".
+var echo = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok {
+		_, _ = w.Write([]byte("anonymous"))
+		return
+	}
+	_, _ = w.Write([]byte(claims.Subject))
+})
+
+func serve(h http.Handler, r *http.Request) (*httptest.ResponseRecorder, middleware.Error) {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	var e middleware.Error
+	if w.Code >= 400 {
+		_ = json.Unmarshal(w.Body.Bytes(), &e)
+	}
+	return w, e
+}
+
+func bearer(token string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+	return r
+}
+
+func TestRequireAuth(t *testing.T) {
+	assert := assert.New(t)
+
+	m := middleware.New(middleware.Config{
+		Verifier: verifier.NewHS256([]byte(jwtSecret), verifier.Options{}),
+	})
+	h := m.RequireAuth(echo)
+
+	w, e := serve(h, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(http.StatusUnauthorized, w.Code)
+	assert.Equal("no_authorization", e.ErrorCode)
+	assert.Equal(http.StatusUnauthorized, e.Code)
+	assert.NotEmpty(w.Header().Get("WWW-Authenticate"))
+
+	w, _ = serve(h, bearer(token(t, time.Hour, nil)))
+	assert.Equal(http.StatusOK, w.Code)
+	assert.Equal("8d7b1b4c-1f0e-4a52-8d3c-3a1e5c2f6b90", w.Body.String())
+
+	w, e = serve(h, bearer(token(t, -time.Minute, nil)))
+	assert.Equal(http.StatusUnauthorized, w.Code)
+	assert.Equal("bad_jwt", e.ErrorCode)
+	assert.Contains(e.Message, "expired")
+}
+
+func TestOptionalAuth(t *testing.T) {
+	assert := assert.New(t)
+
+	m := middleware.New(middleware.Config{
+		Verifier: verifier.NewHS256([]byte(jwtSecret), verifier.Options{}),
+	})
+	h := m.OptionalAuth(echo)
+
+	w, _ := serve(h, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(http.StatusOK, w.Code)
+	assert.Equal("anonymous", w.Body.String())
+
+	w, _ = serve(h, bearer("not a token"))
+	assert.Equal(http.StatusUnauthorized, w.Code)
+}
+
+func TestRejectionMessage(t *testing.T) {
+	assert := assert.New(t)
+
+	var logs bytes.Buffer
+	m := middleware.New(middleware.Config{
+		Verifier: verifier.NewHS256([]byte(jwtSecret), verifier.Options{}),
+		Logger:   slog.New(slog.NewTextHandler(&logs, nil)),
+	})
+
+	// The verifier's error is logged, but not sent to the client.
+	w, e := serve(m.RequireAuth(echo), bearer("not.a.jwt"))
+	assert.Equal(http.StatusUnauthorized, w.Code)
+	assert.Equal("invalid JWT", e.Message)
+	assert.Contains(logs.String(), "token is malformed")
+}
+
+func TestExtractors(t *testing.T) {
+	assert := assert.New(t)
+
+	m := middleware.New(middleware.Config{
+		Verifier: verifier.NewHS256([]byte(jwtSecret), verifier.Options{}),
+		Extractor: middleware.FirstOf(
+			middleware.FromCookie("sb-access-token"),
+			middleware.FromQuery("access_token"),
+		),
+	})
+	h := m.RequireAuth(echo)
+	tok := token(t, time.Hour, nil)
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(&http.Cookie{Name: "sb-access-token", Value: tok})
+	w, _ := serve(h, r)
+	assert.Equal(http.StatusOK, w.Code)
+
+	w, _ = serve(h, httptest.NewRequest(http.MethodGet, "/?access_token="+tok, nil))
+	assert.Equal(http.StatusOK, w.Code)
+
+	// The Authorization header is not consulted by this extractor.
+	w, _ = serve(h, bearer(tok))
+	assert.Equal(http.StatusUnauthorized, w.Code)
+}
+
+func TestGetUserFallback(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	valid := token(t, time.Hour, nil)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+valid {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"code":403,"error_code":"bad_jwt","msg":"invalid JWT"}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(types.User{Email: "user@test.com"})
+	}))
+	defer srv.Close()
+
+	m := middleware.New(middleware.Config{
+		Client: auth.NewWithCustomAuthURL(auth.Config{BaseURL: srv.URL, APIKey: "api_key"}),
+	})
+	h := m.RequireAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, ok := middleware.UserFromContext(r.Context())
+		require.True(ok)
+		claims, ok := middleware.ClaimsFromContext(r.Context())
+		require.True(ok)
+		_, _ = w.Write([]byte(user.Email + " " + claims.Role))
+	}))
+
+	w, _ := serve(h, bearer(valid))
+	assert.Equal(http.StatusOK, w.Code)
+	assert.Equal("user@test.com authenticated", w.Body.String())
+
+	w, e := serve(h, bearer("revoked"))
+	assert.Equal(http.StatusUnauthorized, w.Code)
+	assert.Equal("bad_jwt", e.ErrorCode)
+}
+
+type jwksFetcher struct{}
+
+func (jwksFetcher) GetJWKS(context.Context) (*types.JWKSResponse, error) {
+	return &types.JWKSResponse{}, nil
+}
+
+func TestVerifierFallback(t *testing.T) {
+	assert := assert.New(t)
+
+	var calls atomic.Int32
+	valid := token(t, time.Hour, nil)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if r.Header.Get("Authorization") != "Bearer "+valid {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"code":403,"error_code":"bad_jwt","msg":"invalid JWT"}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(types.User{Email: "user@test.com"})
+	}))
+	defer srv.Close()
+	client := auth.NewWithCustomAuthURL(auth.Config{BaseURL: srv.URL, APIKey: "api_key"})
+
+	// A JWKS verifier cannot check HS256 tokens, so the Auth server does.
+	m := middleware.New(middleware.Config{
+		Verifier: verifier.NewJWKS(jwksFetcher{}, verifier.JWKSOptions{}),
+		Client:   client,
+	})
+	w, _ := serve(m.RequireAuth(echo), bearer(valid))
+	assert.Equal(http.StatusOK, w.Code)
+	assert.Equal("8d7b1b4c-1f0e-4a52-8d3c-3a1e5c2f6b90", w.Body.String())
+	assert.EqualValues(1, calls.Load())
+
+	// A bad signature from a key the verifier has is rejected without asking.
+	forged, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub": "8d7b1b4c-1f0e-4a52-8d3c-3a1e5c2f6b90",
+		"aud": "authenticated",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte("not the secret"))
+	require.NoError(t, err)
+	m = middleware.New(middleware.Config{
+		Verifier: verifier.NewHS256([]byte(jwtSecret), verifier.Options{}),
+		Client:   client,
+	})
+	w, e := serve(m.RequireAuth(echo), bearer(forged))
+	assert.Equal(http.StatusUnauthorized, w.Code)
+	assert.Equal("bad_jwt", e.ErrorCode)
+	assert.EqualValues(1, calls.Load())
+}
+
+func TestAuthorize(t *testing.T) {
+	assert := assert.New(t)
+
+	m := middleware.New(middleware.Config{
+		Verifier: verifier.NewHS256([]byte(jwtSecret), verifier.Options{}),
+	})
+	mux := http.NewServeMux()
+	mux.Handle("/orgs/{org}", m.AuthorizeFunc(func(r *http.Request) policy.Policy {
+		return policy.AllOf(
+			policy.RequireRole("authenticated"),
+			policy.RequireClaim("org_id", r.PathValue("org")),
+		)
+	}, echo))
+	mux.Handle("/mfa", m.Authorize(policy.RequireAAL(types.AAL2), echo))
+
+	tok := token(t, time.Hour, map[string]interface{}{"org_id": "acme"})
+
+	r := bearer(tok)
+	r.URL.Path = "/orgs/acme"
+	w, _ := serve(mux, r)
+	assert.Equal(http.StatusOK, w.Code)
+
+	r = bearer(tok)
+	r.URL.Path = "/orgs/globex"
+	w, e := serve(mux, r)
+	assert.Equal(http.StatusForbidden, w.Code)
+	assert.Equal("claim_mismatch", e.ErrorCode)
+	if assert.NotNil(e.Reason) {
+		assert.Equal(policy.ReasonClaimMismatch, e.Reason.Reason)
+	}
+
+	r = bearer(tok)
+	r.URL.Path = "/mfa"
+	w, e = serve(mux, r)
+	assert.Equal(http.StatusForbidden, w.Code)
+	assert.Equal("insufficient_aal", e.ErrorCode)
+
+	// Authentication is still required.
+	w, _ = serve(mux, httptest.NewRequest(http.MethodGet, "/mfa", nil))
+	assert.Equal(http.StatusUnauthorized, w.Code)
+}
@@ -0,0 +1,131 @@
+// Package observer provides types.Observer implementations.
+package observer
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/mrehanabbasi/supabase-auth-go/types"
+)
+
+var _ types.Observer = &Recorder{}
+
+// Span is a span recorded by a Recorder.
+type Span struct {
+	Endpoint string
+	// Ended is false until the span is ended, in which case the fields below
+	// are zero.
+	Ended   bool
+	Outcome types.Outcome
+	Status  int
+	Err     error
+}
+
+type key struct {
+	endpoint string
+	outcome  types.Outcome
+}
+
+// Recorder keeps everything it observes in memory, so that tests can assert
+// which requests a code path makes. It is safe for concurrent use.
+type Recorder struct {
+	mu        sync.Mutex
+	spans     []*Span
+	counters  map[key]int
+	latencies map[key][]time.Duration
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{
+		counters:  make(map[key]int),
+		latencies: make(map[key][]time.Duration),
+	}
+}
+
+func (r *Recorder) StartSpan(ctx context.Context, endpoint string) (context.Context, types.Span) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	span := &Span{Endpoint: endpoint}
+	r.spans = append(r.spans, span)
+	return ctx, &recordedSpan{recorder: r, span: span}
+}
+
+func (r *Recorder) IncCounter(endpoint string, outcome types.Outcome) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.counters[key{endpoint, outcome}]++
+}
+
+func (r *Recorder) ObserveLatency(endpoint string, outcome types.Outcome, duration time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k := key{endpoint, outcome}
+	r.latencies[k] = append(r.latencies[k], duration)
+}
+
+// Spans returns a copy of the spans started so far, in order.
+func (r *Recorder) Spans() []Span {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	spans := make([]Span, len(r.spans))
+	for i, span := range r.spans {
+		spans[i] = *span
+	}
+	return spans
+}
+
+// Endpoints returns the endpoint of every span started so far, in order.
+func (r *Recorder) Endpoints() []string {
+	var endpoints []string
+	for _, span := range r.Spans() {
+		endpoints = append(endpoints, span.Endpoint)
+	}
+	return endpoints
+}
+
+// Counter returns how many requests to endpoint ended with outcome.
+func (r *Recorder) Counter(endpoint string, outcome types.Outcome) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.counters[key{endpoint, outcome}]
+}
+
+// Latencies returns the latencies observed for requests to endpoint that
+// ended with outcome, in order.
+func (r *Recorder) Latencies(endpoint string, outcome types.Outcome) []time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]time.Duration(nil), r.latencies[key{endpoint, outcome}]...)
+}
+
+// Reset forgets everything observed so far.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.spans = nil
+	r.counters = make(map[key]int)
+	r.latencies = make(map[key][]time.Duration)
+}
+
+type recordedSpan struct {
+	recorder *Recorder
+	span     *Span
+}
+
+func (s *recordedSpan) End(outcome types.Outcome, status int, err error) {
+	s.recorder.mu.Lock()
+	defer s.recorder.mu.Unlock()
+
+	s.span.Ended = true
+	s.span.Outcome = outcome
+	s.span.Status = status
+	s.span.Err = err
+}
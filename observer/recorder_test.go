@@ -0,0 +1,71 @@
+package observer_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	auth "github.com/mrehanabbasi/supabase-auth-go"
+	"github.com/mrehanabbasi/supabase-auth-go/observer"
+	"github.com/mrehanabbasi/supabase-auth-go/types"
+)
+
+func TestRecorder(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	ctx := context.Background()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/signup":
+			_, _ = w.Write([]byte(`{"id":"6b3d2b4e-7b8f-4c7d-9a53-0c4b1f0f4a61","email":"user@test.com"}`))
+		case "/settings":
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"code":503,"error_code":"unexpected_failure","msg":"unavailable"}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"code":400,"error_code":"invalid_credentials","msg":"Invalid login credentials"}`))
+		}
+	}))
+	defer srv.Close()
+
+	rec := observer.NewRecorder()
+	client := auth.NewWithCustomAuthURL(auth.Config{BaseURL: srv.URL, APIKey: "api_key"}).WithObserver(rec)
+
+	_, err := client.Signup(ctx, types.SignupRequest{Email: "user@test.com", Password: "password"})
+	require.NoError(err)
+	_, err = client.SignInWithEmailPassword(ctx, "user@test.com", "wrong")
+	require.Error(err)
+	_, err = client.GetSettings(ctx)
+	require.Error(err)
+
+	assert.Equal([]string{"Signup", "Token", "GetSettings"}, rec.Endpoints())
+	spans := rec.Spans()
+	assert.True(spans[0].Ended)
+	assert.Equal(types.OutcomeSuccess, spans[0].Outcome)
+	assert.Equal(http.StatusOK, spans[0].Status)
+	assert.Equal(types.OutcomeClientError, spans[1].Outcome)
+	assert.Equal(types.OutcomeServerError, spans[2].Outcome)
+
+	assert.Equal(1, rec.Counter("Signup", types.OutcomeSuccess))
+	assert.Equal(1, rec.Counter("Token", types.OutcomeClientError))
+	assert.Zero(rec.Counter("Token", types.OutcomeSuccess))
+	assert.Len(rec.Latencies("GetSettings", types.OutcomeServerError), 1)
+
+	// Requests that get no response are network errors.
+	srv.Close()
+	rec.Reset()
+	_, err = client.GetSettings(ctx)
+	require.Error(err)
+	assert.Equal(1, rec.Counter("GetSettings", types.OutcomeNetworkError))
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = client.GetSettings(cancelled)
+	require.Error(err)
+	assert.Equal(1, rec.Counter("GetSettings", types.OutcomeCanceled))
+}
@@ -0,0 +1,206 @@
+package pkce
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/mrehanabbasi/supabase-auth-go/internal/seal"
+	"github.com/mrehanabbasi/supabase-auth-go/types"
+)
+
+const defaultCookieName = "sb-pkce"
+
+var (
+	_ types.VerifierStore = &boundCookieStore{}
+
+	ErrInvalidSecret = seal.ErrInvalidSecret
+)
+
+type CookieOptions struct {
+	// Name is the prefix of the cookie names. Each flow uses its own cookie,
+	// named Name-<state>. Defaults to "sb-pkce".
+	Name string
+	// Path defaults to "/".
+	Path   string
+	Domain string
+	// Secure should be set when the site is served over HTTPS.
+	Secure bool
+	// SameSite defaults to http.SameSiteLaxMode. Strict mode would stop the
+	// cookie being sent when the Auth server redirects back to the site.
+	SameSite http.SameSite
+	// TTL is how long the user has to complete the flow. Defaults to 10
+	// minutes.
+	TTL time.Duration
+}
+
+// CookieStore keeps each verifier in an encrypted and signed cookie on the
+// user's browser, so no server state is needed and any instance of the
+// service can complete the flow.
+//
+// A CookieStore is not a types.VerifierStore itself, since it needs the
+// current request and response. Call Bind in each handler to get one.
+type CookieStore struct {
+	sealer *seal.Sealer
+	opts   CookieOptions
+}
+
+type cookiePayload struct {
+	Verifier  string `json:"v"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// Set up a new cookie store.
+//
+// secret: Used to derive the encryption and signing keys. It must be at least
+// 32 bytes long, and the same for every instance of the service.
+func NewCookieStore(secret []byte, opts CookieOptions) (*CookieStore, error) {
+	sealer, err := seal.New(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Name == "" {
+		opts.Name = defaultCookieName
+	}
+	if opts.Path == "" {
+		opts.Path = "/"
+	}
+	if opts.SameSite == 0 {
+		opts.SameSite = http.SameSiteLaxMode
+	}
+	if opts.TTL <= 0 {
+		opts.TTL = DefaultTTL
+	}
+
+	return &CookieStore{
+		sealer: sealer,
+		opts:   opts,
+	}, nil
+}
+
+// Bind returns a store that reads verifiers from the cookies of r, and writes
+// them to w. Use it with client.WithVerifierStore for the duration of a
+// single request.
+func (s *CookieStore) Bind(w http.ResponseWriter, r *http.Request) types.VerifierStore {
+	return &boundCookieStore{
+		CookieStore: s,
+		w:           w,
+		r:           r,
+	}
+}
+
+type boundCookieStore struct {
+	*CookieStore
+
+	w http.ResponseWriter
+	r *http.Request
+
+	// deleted holds the states whose cookie was deleted, which are still sent
+	// with r but must not be loaded again.
+	mu      sync.Mutex
+	deleted map[string]bool
+}
+
+func (s *boundCookieStore) Save(_ context.Context, state string, verifier string) error {
+	data, err := json.Marshal(cookiePayload{
+		Verifier:  verifier,
+		ExpiresAt: time.Now().Add(s.opts.TTL).Unix(),
+	})
+	if err != nil {
+		return err
+	}
+
+	name := s.cookieName(state)
+	value, err := s.sealer.Seal(name, data)
+	if err != nil {
+		return err
+	}
+
+	http.SetCookie(s.w, s.cookie(name, value, int(s.opts.TTL.Seconds())))
+	return nil
+}
+
+func (s *boundCookieStore) Load(_ context.Context, state string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.load(state)
+}
+
+// Take deletes the cookie as it loads it. The cookie stays valid until it
+// expires if it is replayed by the browser, but the Auth server only lets an
+// auth code be exchanged once.
+func (s *boundCookieStore) Take(_ context.Context, state string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	verifier, err := s.load(state)
+	if err != nil {
+		return "", err
+	}
+	s.delete(state)
+	return verifier, nil
+}
+
+func (s *boundCookieStore) Delete(_ context.Context, state string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.delete(state)
+	return nil
+}
+
+func (s *boundCookieStore) load(state string) (string, error) {
+	if s.deleted[state] {
+		return "", types.ErrVerifierNotFound
+	}
+
+	name := s.cookieName(state)
+	c, err := s.r.Cookie(name)
+	if err != nil {
+		return "", types.ErrVerifierNotFound
+	}
+
+	data, err := s.sealer.Open(name, c.Value)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", types.ErrVerifierNotFound, err)
+	}
+	var payload cookiePayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return "", fmt.Errorf("%w: %w", types.ErrVerifierNotFound, err)
+	}
+	if time.Now().Unix() > payload.ExpiresAt {
+		return "", types.ErrVerifierNotFound
+	}
+
+	return payload.Verifier, nil
+}
+
+func (s *boundCookieStore) delete(state string) {
+	if s.deleted == nil {
+		s.deleted = make(map[string]bool)
+	}
+	s.deleted[state] = true
+	http.SetCookie(s.w, s.cookie(s.cookieName(state), "", -1))
+}
+
+func (s *CookieStore) cookieName(state string) string {
+	return s.opts.Name + "-" + state
+}
+
+func (s *CookieStore) cookie(name string, value string, maxAge int) *http.Cookie {
+	return &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     s.opts.Path,
+		Domain:   s.opts.Domain,
+		MaxAge:   maxAge,
+		Secure:   s.opts.Secure,
+		HttpOnly: true,
+		SameSite: s.opts.SameSite,
+	}
+}
@@ -0,0 +1,100 @@
+package pkce
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/mrehanabbasi/supabase-auth-go/types"
+)
+
+// DefaultTTL is how long verifiers are kept if no TTL is configured.
+const DefaultTTL = 10 * time.Minute
+
+var _ types.VerifierStore = &MemoryStore{}
+
+// MemoryStore keeps verifiers in memory until they expire. Verifiers are lost
+// when the process exits, and are not shared between instances, so use the
+// CookieStore if requests may be served by more than one process.
+type MemoryStore struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+}
+
+type memoryEntry struct {
+	verifier  string
+	expiresAt time.Time
+}
+
+// Set up a new in-memory store.
+//
+// ttl: How long a verifier is kept, i.e. how long the user has to complete
+// the flow. Defaults to 10 minutes.
+func NewMemoryStore(ttl time.Duration) *MemoryStore {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &MemoryStore{
+		ttl:     ttl,
+		entries: make(map[string]memoryEntry),
+	}
+}
+
+// Bind returns the store itself. It lets a MemoryStore be used wherever a
+// CookieStore's Bind method is expected.
+func (s *MemoryStore) Bind(http.ResponseWriter, *http.Request) types.VerifierStore {
+	return s
+}
+
+func (s *MemoryStore) Save(_ context.Context, state string, verifier string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	// Abandoned flows are never loaded, so drop them here to bound memory.
+	for k, e := range s.entries {
+		if now.After(e.expiresAt) {
+			delete(s.entries, k)
+		}
+	}
+
+	s.entries[state] = memoryEntry{
+		verifier:  verifier,
+		expiresAt: now.Add(s.ttl),
+	}
+	return nil
+}
+
+func (s *MemoryStore) Load(_ context.Context, state string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[state]
+	if !ok || time.Now().After(e.expiresAt) {
+		return "", types.ErrVerifierNotFound
+	}
+	return e.verifier, nil
+}
+
+func (s *MemoryStore) Take(_ context.Context, state string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[state]
+	delete(s.entries, state)
+	if !ok || time.Now().After(e.expiresAt) {
+		return "", types.ErrVerifierNotFound
+	}
+	return e.verifier, nil
+}
+
+func (s *MemoryStore) Delete(_ context.Context, state string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, state)
+	return nil
+}
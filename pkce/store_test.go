@@ -0,0 +1,164 @@
+package pkce_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrehanabbasi/supabase-auth-go/pkce"
+	"github.com/mrehanabbasi/supabase-auth-go/types"
+)
+
+var secret = bytes.Repeat([]byte{1}, 32)
+
+func TestMemoryStore(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	ctx := context.Background()
+
+	store := pkce.NewMemoryStore(50 * time.Millisecond)
+
+	_, err := store.Load(ctx, "state")
+	require.ErrorIs(err, types.ErrVerifierNotFound)
+
+	require.NoError(store.Save(ctx, "state", "verifier"))
+	verifier, err := store.Load(ctx, "state")
+	require.NoError(err)
+	assert.Equal("verifier", verifier)
+
+	require.NoError(store.Delete(ctx, "state"))
+	require.NoError(store.Delete(ctx, "state"))
+	_, err = store.Load(ctx, "state")
+	assert.ErrorIs(err, types.ErrVerifierNotFound)
+
+	require.NoError(store.Save(ctx, "state", "verifier"))
+	verifier, err = store.Take(ctx, "state")
+	require.NoError(err)
+	assert.Equal("verifier", verifier)
+	_, err = store.Take(ctx, "state")
+	assert.ErrorIs(err, types.ErrVerifierNotFound)
+
+	// Verifiers expire.
+	require.NoError(store.Save(ctx, "state", "verifier"))
+	time.Sleep(100 * time.Millisecond)
+	_, err = store.Load(ctx, "state")
+	assert.ErrorIs(err, types.ErrVerifierNotFound)
+}
+
+func TestCookieStore(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	ctx := context.Background()
+
+	_, err := pkce.NewCookieStore([]byte("short"), pkce.CookieOptions{})
+	require.ErrorIs(err, pkce.ErrInvalidSecret)
+
+	cs, err := pkce.NewCookieStore(secret, pkce.CookieOptions{Secure: true})
+	require.NoError(err)
+
+	// Start the flow.
+	w := httptest.NewRecorder()
+	require.NoError(cs.Bind(w, httptest.NewRequest(http.MethodGet, "/login", nil)).Save(ctx, "state", "verifier"))
+	cookies := w.Result().Cookies()
+	require.Len(cookies, 1)
+	cookie := cookies[0]
+	assert.Equal("sb-pkce-state", cookie.Name)
+	assert.NotContains(cookie.Value, "verifier")
+	assert.True(cookie.HttpOnly)
+	assert.True(cookie.Secure)
+	assert.Equal(http.SameSiteLaxMode, cookie.SameSite)
+
+	// Complete it in the callback.
+	r := httptest.NewRequest(http.MethodGet, "/callback", nil)
+	r.AddCookie(cookie)
+	w = httptest.NewRecorder()
+	store := cs.Bind(w, r)
+	verifier, err := store.Load(ctx, "state")
+	require.NoError(err)
+	assert.Equal("verifier", verifier)
+
+	// Other states have no cookie.
+	_, err = store.Load(ctx, "other")
+	assert.ErrorIs(err, types.ErrVerifierNotFound)
+
+	verifier, err = store.Take(ctx, "state")
+	require.NoError(err)
+	assert.Equal("verifier", verifier)
+	cookies = w.Result().Cookies()
+	require.Len(cookies, 1)
+	assert.Equal("sb-pkce-state", cookies[0].Name)
+	assert.Negative(cookies[0].MaxAge)
+
+	// The cookie is still sent with the request, but was taken.
+	_, err = store.Load(ctx, "state")
+	assert.ErrorIs(err, types.ErrVerifierNotFound)
+	_, err = store.Take(ctx, "state")
+	assert.ErrorIs(err, types.ErrVerifierNotFound)
+
+	// A cookie cannot be moved to another state.
+	r = httptest.NewRequest(http.MethodGet, "/callback", nil)
+	r.AddCookie(&http.Cookie{Name: "sb-pkce-other", Value: cookie.Value})
+	_, err = cs.Bind(httptest.NewRecorder(), r).Load(ctx, "other")
+	assert.ErrorIs(err, types.ErrVerifierNotFound)
+
+	// Tampered cookies are rejected.
+	r = httptest.NewRequest(http.MethodGet, "/callback", nil)
+	r.AddCookie(&http.Cookie{Name: cookie.Name, Value: "x" + cookie.Value})
+	_, err = cs.Bind(httptest.NewRecorder(), r).Load(ctx, "state")
+	assert.ErrorIs(err, types.ErrVerifierNotFound)
+
+	// Cookies sealed with another secret are rejected.
+	other, err := pkce.NewCookieStore(bytes.Repeat([]byte{2}, 32), pkce.CookieOptions{})
+	require.NoError(err)
+	r = httptest.NewRequest(http.MethodGet, "/callback", nil)
+	r.AddCookie(cookie)
+	_, err = other.Bind(httptest.NewRecorder(), r).Load(ctx, "state")
+	assert.ErrorIs(err, types.ErrVerifierNotFound)
+}
+
+func TestStoreTakeConcurrent(t *testing.T) {
+	ctx := context.Background()
+
+	cs, err := pkce.NewCookieStore(secret, pkce.CookieOptions{})
+	require.NoError(t, err)
+	w := httptest.NewRecorder()
+	require.NoError(t, cs.Bind(w, httptest.NewRequest(http.MethodGet, "/login", nil)).Save(ctx, "state", "verifier"))
+	r := httptest.NewRequest(http.MethodGet, "/callback", nil)
+	r.AddCookie(w.Result().Cookies()[0])
+
+	memory := pkce.NewMemoryStore(0)
+	require.NoError(t, memory.Save(ctx, "state", "verifier"))
+
+	for name, store := range map[string]types.VerifierStore{
+		"memory": memory,
+		"cookie": cs.Bind(httptest.NewRecorder(), r),
+	} {
+		t.Run(name, func(t *testing.T) {
+			var wg sync.WaitGroup
+			var taken atomic.Int32
+			for range 50 {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					verifier, err := store.Take(ctx, "state")
+					if err == nil {
+						assert.Equal(t, "verifier", verifier)
+						taken.Add(1)
+					} else {
+						assert.ErrorIs(t, err, types.ErrVerifierNotFound)
+					}
+				}()
+			}
+			wg.Wait()
+			assert.EqualValues(t, 1, taken.Load())
+		})
+	}
+}
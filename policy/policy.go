@@ -0,0 +1,234 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mrehanabbasi/supabase-auth-go/types"
+)
+
+type Reason string
+
+const (
+	ReasonMissingClaims     Reason = "missing_claims"
+	ReasonInsufficientRole  Reason = "insufficient_role"
+	ReasonInsufficientAAL   Reason = "insufficient_aal"
+	ReasonClaimMismatch     Reason = "claim_mismatch"
+	ReasonNoPolicySatisfied Reason = "no_policy_satisfied"
+)
+
+// Error describes why claims did not satisfy a policy. Use errors.As to get
+// it from the error returned by a Policy.
+type Error struct {
+	Reason  Reason `json:"reason"`
+	Message string `json:"message"`
+	// Causes holds the failures of each alternative when no policy passed to
+	// AnyOf was satisfied.
+	Causes []*Error `json:"causes,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Policy decides whether the claims of a verified access token are
+// authorized. It returns nil if they are, or an *Error describing why not.
+//
+// Policies can be called directly, combined with AllOf and AnyOf, or enforced
+// on HTTP handlers with middleware.Authorize.
+type Policy func(claims *types.Claims) error
+
+var aalRank = map[types.AAL]int{
+	types.AAL1: 1,
+	types.AAL2: 2,
+}
+
+// RequireRole requires the role claim to be one of roles.
+func RequireRole(roles ...string) Policy {
+	return func(claims *types.Claims) error {
+		if claims == nil {
+			return missingClaims()
+		}
+		if slices.Contains(roles, claims.Role) {
+			return nil
+		}
+		return &Error{
+			Reason:  ReasonInsufficientRole,
+			Message: fmt.Sprintf("role %q is not one of %s", claims.Role, strings.Join(roles, ", ")),
+		}
+	}
+}
+
+// RequireAAL requires the authenticator assurance level to be at least min.
+// For example, RequireAAL(types.AAL2) requires the user to have verified an
+// MFA factor during the session.
+//
+// It panics if min is not a known level, as the policy would otherwise let
+// every session through.
+func RequireAAL(min types.AAL) Policy {
+	if aalRank[min] == 0 {
+		panic(fmt.Sprintf("policy: unknown AAL %q", min))
+	}
+	return func(claims *types.Claims) error {
+		if claims == nil {
+			return missingClaims()
+		}
+		if aalRank[claims.AAL] >= aalRank[min] {
+			return nil
+		}
+		return &Error{
+			Reason:  ReasonInsufficientAAL,
+			Message: fmt.Sprintf("AAL %s is required, but the session has %q", min, claims.AAL),
+		}
+	}
+}
+
+// RequireClaim requires the value at path in app_metadata to equal want. If
+// the value is an array, it must contain want. Numbers are compared by
+// value, so 1 matches the JSON number 1.0.
+//
+// path is a dot separated list of object keys and array indexes, e.g.
+// "org_id" or "orgs.0.id".
+func RequireClaim(path string, want interface{}) Policy {
+	return RequireClaimFunc(path, func(value interface{}) bool {
+		if items, ok := value.([]interface{}); ok {
+			return slices.ContainsFunc(items, func(item interface{}) bool {
+				return equal(item, want)
+			})
+		}
+		return equal(value, want)
+	})
+}
+
+// RequireClaimFunc requires match to return true for the value at path in
+// app_metadata. See RequireClaim for the path syntax. match is not called if
+// there is no value at path.
+func RequireClaimFunc(path string, match func(value interface{}) bool) Policy {
+	return func(claims *types.Claims) error {
+		if claims == nil {
+			return missingClaims()
+		}
+		value, ok := lookup(claims.AppMetadata, path)
+		if !ok {
+			return &Error{
+				Reason:  ReasonClaimMismatch,
+				Message: fmt.Sprintf("app_metadata.%s is not set", path),
+			}
+		}
+		if !match(value) {
+			return &Error{
+				Reason:  ReasonClaimMismatch,
+				Message: fmt.Sprintf("app_metadata.%s does not match", path),
+			}
+		}
+		return nil
+	}
+}
+
+// AllOf requires every policy to be satisfied. It returns the first failure.
+func AllOf(policies ...Policy) Policy {
+	return func(claims *types.Claims) error {
+		for _, p := range policies {
+			if err := p(claims); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// AnyOf requires at least one policy to be satisfied. If none is, the
+// returned error lists the failure of each.
+func AnyOf(policies ...Policy) Policy {
+	return func(claims *types.Claims) error {
+		causes := make([]*Error, 0, len(policies))
+		for _, p := range policies {
+			err := p(claims)
+			if err == nil {
+				return nil
+			}
+			causes = append(causes, asError(err))
+		}
+		return &Error{
+			Reason:  ReasonNoPolicySatisfied,
+			Message: "none of the required policies were satisfied",
+			Causes:  causes,
+		}
+	}
+}
+
+func missingClaims() error {
+	return &Error{
+		Reason:  ReasonMissingClaims,
+		Message: "request is not authenticated",
+	}
+}
+
+// asError converts errors returned by custom policies into an *Error.
+func asError(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	return &Error{
+		Reason:  ReasonClaimMismatch,
+		Message: err.Error(),
+	}
+}
+
+func lookup(value interface{}, path string) (interface{}, bool) {
+	for _, key := range strings.Split(path, ".") {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			next, ok := v[key]
+			if !ok {
+				return nil, false
+			}
+			value = next
+		case []interface{}:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, false
+			}
+			value = v[i]
+		default:
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+func equal(got, want interface{}) bool {
+	g, gok := toFloat(got)
+	w, wok := toFloat(want)
+	if gok && wok {
+		return g == w
+	}
+	return reflect.DeepEqual(got, want)
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	}
+	return 0, false
+}
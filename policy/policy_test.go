@@ -0,0 +1,78 @@
+package policy_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrehanabbasi/supabase-auth-go/policy"
+	"github.com/mrehanabbasi/supabase-auth-go/types"
+)
+
+func reason(t *testing.T, err error) policy.Reason {
+	var pErr *policy.Error
+	require.True(t, errors.As(err, &pErr), "expected a *policy.Error, got %v", err)
+	return pErr.Reason
+}
+
+func TestPolicies(t *testing.T) {
+	assert := assert.New(t)
+
+	claims := &types.Claims{
+		Role: "authenticated",
+		AAL:  types.AAL1,
+		AppMetadata: map[string]interface{}{
+			"org_id": "acme",
+			"tier":   float64(2),
+			"roles":  []interface{}{"editor", "viewer"},
+			"orgs": []interface{}{
+				map[string]interface{}{"id": "acme"},
+			},
+		},
+	}
+
+	assert.NoError(policy.RequireRole("authenticated", "service_role")(claims))
+	assert.Equal(policy.ReasonInsufficientRole, reason(t, policy.RequireRole("service_role")(claims)))
+
+	assert.NoError(policy.RequireAAL(types.AAL1)(claims))
+	assert.Equal(policy.ReasonInsufficientAAL, reason(t, policy.RequireAAL(types.AAL2)(claims)))
+	assert.PanicsWithValue(`policy: unknown AAL "AAL2"`, func() { policy.RequireAAL("AAL2") })
+
+	assert.NoError(policy.RequireClaim("org_id", "acme")(claims))
+	assert.NoError(policy.RequireClaim("tier", 2)(claims))
+	assert.NoError(policy.RequireClaim("roles", "editor")(claims))
+	assert.NoError(policy.RequireClaim("orgs.0.id", "acme")(claims))
+	assert.Equal(policy.ReasonClaimMismatch, reason(t, policy.RequireClaim("org_id", "globex")(claims)))
+	assert.Equal(policy.ReasonClaimMismatch, reason(t, policy.RequireClaim("orgs.1.id", "acme")(claims)))
+	assert.Equal(policy.ReasonClaimMismatch, reason(t, policy.RequireClaim("missing", "x")(claims)))
+
+	assert.Equal(policy.ReasonMissingClaims, reason(t, policy.RequireRole("authenticated")(nil)))
+
+	// Combinators.
+	assert.NoError(policy.AllOf(
+		policy.RequireRole("authenticated"),
+		policy.RequireClaim("org_id", "acme"),
+	)(claims))
+	assert.Equal(policy.ReasonInsufficientAAL, reason(t, policy.AllOf(
+		policy.RequireRole("authenticated"),
+		policy.RequireAAL(types.AAL2),
+	)(claims)))
+
+	assert.NoError(policy.AnyOf(
+		policy.RequireRole("service_role"),
+		policy.RequireClaim("roles", "editor"),
+	)(claims))
+
+	err := policy.AnyOf(
+		policy.RequireRole("service_role"),
+		policy.RequireAAL(types.AAL2),
+	)(claims)
+	var pErr *policy.Error
+	require.ErrorAs(t, err, &pErr)
+	assert.Equal(policy.ReasonNoPolicySatisfied, pErr.Reason)
+	require.Len(t, pErr.Causes, 2)
+	assert.Equal(policy.ReasonInsufficientRole, pErr.Causes[0].Reason)
+	assert.Equal(policy.ReasonInsufficientAAL, pErr.Causes[1].Reason)
+}
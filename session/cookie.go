@@ -0,0 +1,292 @@
+package session
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mrehanabbasi/supabase-auth-go/internal/seal"
+	"github.com/mrehanabbasi/supabase-auth-go/types"
+)
+
+const (
+	defaultChunkSize    = 3180
+	defaultCookieMaxAge = 400 * 24 * time.Hour
+
+	// base64Prefix marks plain cookie values, in the same format as
+	// @supabase/ssr, so that sessions can be shared with its server clients.
+	base64Prefix = "base64-"
+)
+
+var (
+	_ types.SessionStore = &boundCookieStore{}
+
+	ErrInvalidSecret = seal.ErrInvalidSecret
+)
+
+type CookieOptions struct {
+	// Path defaults to "/".
+	Path   string
+	Domain string
+	// Secure should be set when the site is served over HTTPS.
+	Secure bool
+	// SameSite defaults to http.SameSiteLaxMode, so that the session is sent
+	// when the user follows a link to the site.
+	SameSite http.SameSite
+	// MaxAge is how long the browser keeps the cookies. Defaults to 400 days,
+	// the longest browsers allow.
+	MaxAge time.Duration
+	// ChunkSize is the longest value a single cookie may hold. Longer
+	// sessions are split across several cookies. Defaults to 3180 bytes,
+	// which leaves room for the cookie's name and attributes within the 4KB
+	// browsers accept.
+	ChunkSize int
+}
+
+// CookieStore keeps sessions in cookies on the user's browser, so no server
+// state is needed. The session is stored under a cookie named after its key.
+// Sessions too long for one cookie are split into cookies named <key>.0,
+// <key>.1 and so on.
+//
+// Use NewCookieStore for plain cookies that @supabase/ssr can read, or
+// NewEncryptedCookieStore to encrypt and sign them.
+//
+// A CookieStore is not a types.SessionStore itself, since it needs the
+// current request and response. Call Bind in each handler to get one.
+type CookieStore struct {
+	sealer *seal.Sealer
+	opts   CookieOptions
+}
+
+// Set up a new cookie store that saves sessions as base64 encoded JSON.
+func NewCookieStore(opts CookieOptions) *CookieStore {
+	if opts.Path == "" {
+		opts.Path = "/"
+	}
+	if opts.SameSite == 0 {
+		opts.SameSite = http.SameSiteLaxMode
+	}
+	if opts.MaxAge <= 0 {
+		opts.MaxAge = defaultCookieMaxAge
+	}
+	if opts.ChunkSize <= 0 {
+		opts.ChunkSize = defaultChunkSize
+	}
+
+	return &CookieStore{
+		opts: opts,
+	}
+}
+
+// Set up a new cookie store that saves sessions encrypted and signed, so they
+// cannot be read or forged by the browser.
+//
+// secret: Used to derive the encryption and signing keys. It must be at least
+// 32 bytes long, and the same for every instance of the service.
+func NewEncryptedCookieStore(secret []byte, opts CookieOptions) (*CookieStore, error) {
+	sealer, err := seal.New(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	s := NewCookieStore(opts)
+	s.sealer = sealer
+	return s, nil
+}
+
+// Bind returns a store that reads sessions from the cookies of r, and writes
+// them to w. Use it with client.WithSessionStore or Restore for the duration
+// of a single request.
+//
+// Sessions saved or deleted through the returned store are visible to later
+// calls to Load, even though r still carries the old cookies.
+func (s *CookieStore) Bind(w http.ResponseWriter, r *http.Request) types.SessionStore {
+	return &boundCookieStore{
+		CookieStore: s,
+		w:           w,
+		r:           r,
+		written:     make(map[string]*types.Session),
+	}
+}
+
+type boundCookieStore struct {
+	*CookieStore
+
+	w http.ResponseWriter
+	r *http.Request
+
+	// written holds sessions saved, or nil for those deleted, during this
+	// request.
+	written map[string]*types.Session
+}
+
+func (s *boundCookieStore) Load(_ context.Context, key string) (*types.Session, error) {
+	if session, ok := s.written[key]; ok {
+		if session == nil {
+			return nil, types.ErrSessionNotStored
+		}
+		copied := *session
+		return &copied, nil
+	}
+
+	name := cookieName(key)
+	value := ""
+	if c, err := s.r.Cookie(name); err == nil {
+		value = c.Value
+	} else {
+		var b strings.Builder
+		for i := 0; ; i++ {
+			c, err := s.r.Cookie(chunkName(name, i))
+			if err != nil {
+				break
+			}
+			b.WriteString(c.Value)
+		}
+		value = b.String()
+	}
+	if value == "" {
+		return nil, types.ErrSessionNotStored
+	}
+
+	data, err := s.decode(name, value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", types.ErrSessionNotStored, err)
+	}
+	var session types.Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, fmt.Errorf("%w: failed to decode stored session: %w", types.ErrSessionNotStored, err)
+	}
+	return &session, nil
+}
+
+func (s *boundCookieStore) Save(_ context.Context, key string, session types.Session) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+
+	name := cookieName(key)
+	value, err := s.encode(name, data)
+	if err != nil {
+		return err
+	}
+
+	s.clearCookies(name)
+	maxAge := int(s.opts.MaxAge.Seconds())
+	if len(value) <= s.opts.ChunkSize {
+		http.SetCookie(s.w, s.cookie(name, value, maxAge))
+	} else {
+		for i := 0; len(value) > 0; i++ {
+			n := min(s.opts.ChunkSize, len(value))
+			http.SetCookie(s.w, s.cookie(chunkName(name, i), value[:n], maxAge))
+			value = value[n:]
+		}
+	}
+	s.expireStale(name)
+
+	s.written[key] = &session
+	return nil
+}
+
+func (s *boundCookieStore) Delete(_ context.Context, key string) error {
+	name := cookieName(key)
+	s.clearCookies(name)
+	s.expireStale(name)
+
+	s.written[key] = nil
+	return nil
+}
+
+// clearCookies removes the cookies for name that were already set on the
+// response, so that saving twice in one request does not send both.
+func (s *boundCookieStore) clearCookies(name string) {
+	header := s.w.Header()
+	var kept []string
+	for _, line := range header.Values("Set-Cookie") {
+		if c, err := http.ParseSetCookie(line); err == nil && isCookieFor(c.Name, name) {
+			continue
+		}
+		kept = append(kept, line)
+	}
+	header.Del("Set-Cookie")
+	for _, line := range kept {
+		header.Add("Set-Cookie", line)
+	}
+}
+
+// expireStale expires the cookies for name that the request carries but the
+// response does not set, such as chunks left over from a longer session.
+func (s *boundCookieStore) expireStale(name string) {
+	set := make(map[string]bool)
+	for _, line := range s.w.Header().Values("Set-Cookie") {
+		if c, err := http.ParseSetCookie(line); err == nil {
+			set[c.Name] = true
+		}
+	}
+	for _, c := range s.r.Cookies() {
+		if isCookieFor(c.Name, name) && !set[c.Name] {
+			http.SetCookie(s.w, s.cookie(c.Name, "", -1))
+			set[c.Name] = true
+		}
+	}
+}
+
+func (s *CookieStore) encode(name string, data []byte) (string, error) {
+	if s.sealer != nil {
+		return s.sealer.Seal(name, data)
+	}
+	return base64Prefix + base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func (s *CookieStore) decode(name string, value string) ([]byte, error) {
+	if s.sealer != nil {
+		return s.sealer.Open(name, value)
+	}
+	if encoded, ok := strings.CutPrefix(value, base64Prefix); ok {
+		return base64.RawURLEncoding.DecodeString(encoded)
+	}
+	// @supabase/ssr wrote plain JSON before switching to base64.
+	return []byte(value), nil
+}
+
+func (s *CookieStore) cookie(name string, value string, maxAge int) *http.Cookie {
+	return &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     s.opts.Path,
+		Domain:   s.opts.Domain,
+		MaxAge:   maxAge,
+		Secure:   s.opts.Secure,
+		HttpOnly: true,
+		SameSite: s.opts.SameSite,
+	}
+}
+
+// cookieName escapes key into a valid cookie name. The default session key is
+// left unchanged.
+func cookieName(key string) string {
+	return url.QueryEscape(key)
+}
+
+func chunkName(name string, i int) string {
+	return name + "." + strconv.Itoa(i)
+}
+
+// isCookieFor reports whether cookie is name itself or one of its chunks.
+func isCookieFor(cookie string, name string) bool {
+	if cookie == name {
+		return true
+	}
+	suffix, ok := strings.CutPrefix(cookie, name+".")
+	if !ok || suffix == "" {
+		return false
+	}
+	_, err := strconv.Atoi(suffix)
+	return err == nil
+}
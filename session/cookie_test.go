@@ -0,0 +1,234 @@
+package session_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrehanabbasi/supabase-auth-go/session"
+	"github.com/mrehanabbasi/supabase-auth-go/types"
+)
+
+// setCookies parses the cookies set on w so far. Unlike w.Result, it is not
+// a snapshot taken on first use.
+func setCookies(w *httptest.ResponseRecorder) []*http.Cookie {
+	return (&http.Response{Header: w.Header()}).Cookies()
+}
+
+// nextRequest returns a request carrying the cookies w set, as a browser
+// would send them.
+func nextRequest(w *httptest.ResponseRecorder, prev *http.Request) *http.Request {
+	jar := make(map[string]*http.Cookie)
+	if prev != nil {
+		for _, c := range prev.Cookies() {
+			jar[c.Name] = c
+		}
+	}
+	for _, c := range setCookies(w) {
+		if c.MaxAge < 0 {
+			delete(jar, c.Name)
+		} else {
+			jar[c.Name] = c
+		}
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, c := range jar {
+		r.AddCookie(&http.Cookie{Name: c.Name, Value: c.Value})
+	}
+	return r
+}
+
+func cookieNames(w *httptest.ResponseRecorder) []string {
+	var names []string
+	for _, c := range setCookies(w) {
+		if c.MaxAge >= 0 {
+			names = append(names, c.Name)
+		}
+	}
+	return names
+}
+
+func TestCookieStore(t *testing.T) {
+	encrypted, err := session.NewEncryptedCookieStore(bytes.Repeat([]byte{1}, 32), session.CookieOptions{
+		Secure:    true,
+		ChunkSize: 1200,
+	})
+	require.NoError(t, err)
+
+	stores := map[string]*session.CookieStore{
+		"plain":     session.NewCookieStore(session.CookieOptions{Secure: true, ChunkSize: 1200}),
+		"encrypted": encrypted,
+	}
+	for name, cs := range stores {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+			ctx := context.Background()
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			w := httptest.NewRecorder()
+			store := cs.Bind(w, r)
+
+			_, err := store.Load(ctx, types.DefaultSessionKey)
+			require.ErrorIs(err, types.ErrSessionNotStored)
+
+			// Long sessions are split across several cookies.
+			long := types.Session{
+				AccessToken:  strings.Repeat("a", 4000),
+				RefreshToken: "refresh",
+				ExpiresAt:    123,
+			}
+			require.NoError(store.Save(ctx, types.DefaultSessionKey, long))
+			assert.Greater(len(cookieNames(w)), 1)
+			for _, c := range setCookies(w) {
+				assert.True(c.HttpOnly)
+				assert.True(c.Secure)
+				assert.Equal(http.SameSiteLaxMode, c.SameSite)
+			}
+
+			// Saving again in the same request replaces the cookies.
+			short := types.Session{AccessToken: "access", RefreshToken: "refresh", ExpiresAt: 123}
+			require.NoError(store.Save(ctx, types.DefaultSessionKey, short))
+			assert.Equal([]string{types.DefaultSessionKey}, cookieNames(w))
+
+			loaded, err := store.Load(ctx, types.DefaultSessionKey)
+			require.NoError(err)
+			assert.Equal(short, *loaded)
+
+			// A later request reads the cookies back.
+			r = nextRequest(w, nil)
+			w = httptest.NewRecorder()
+			loaded, err = cs.Bind(w, r).Load(ctx, types.DefaultSessionKey)
+			require.NoError(err)
+			assert.Equal(short, *loaded)
+
+			// Growing the session replaces the single cookie with chunks.
+			require.NoError(cs.Bind(w, r).Save(ctx, types.DefaultSessionKey, long))
+			r = nextRequest(w, r)
+			assert.NotContains(cookieNames(w), types.DefaultSessionKey)
+			w = httptest.NewRecorder()
+			loaded, err = cs.Bind(w, r).Load(ctx, types.DefaultSessionKey)
+			require.NoError(err)
+			assert.Equal(long, *loaded)
+
+			// Deleting expires every chunk.
+			require.NoError(cs.Bind(w, r).Delete(ctx, types.DefaultSessionKey))
+			assert.Empty(cookieNames(w))
+			r = nextRequest(w, r)
+			assert.Empty(r.Cookies())
+		})
+	}
+}
+
+func TestEncryptedCookieStore(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	ctx := context.Background()
+
+	_, err := session.NewEncryptedCookieStore([]byte("short"), session.CookieOptions{})
+	require.ErrorIs(err, session.ErrInvalidSecret)
+
+	cs, err := session.NewEncryptedCookieStore(bytes.Repeat([]byte{1}, 32), session.CookieOptions{})
+	require.NoError(err)
+
+	w := httptest.NewRecorder()
+	require.NoError(cs.Bind(w, httptest.NewRequest(http.MethodGet, "/", nil)).Save(ctx, "key", types.Session{
+		AccessToken: "secret-access-token",
+	}))
+	assert.NotContains(w.Header().Get("Set-Cookie"), "secret-access-token")
+
+	// Cookies sealed with another secret are ignored.
+	other, err := session.NewEncryptedCookieStore(bytes.Repeat([]byte{2}, 32), session.CookieOptions{})
+	require.NoError(err)
+	_, err = other.Bind(httptest.NewRecorder(), nextRequest(w, nil)).Load(ctx, "key")
+	assert.ErrorIs(err, types.ErrSessionNotStored)
+}
+
+func TestCookieStoreClient(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	ctx := context.Background()
+
+	ts := newTokenServer(t)
+	cs := session.NewCookieStore(session.CookieOptions{})
+
+	_, _, err := cs.Client(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil), ts.client(), "", session.Config{})
+	require.ErrorIs(err, types.ErrSessionNotStored)
+
+	// A fresh session is used as is.
+	w := httptest.NewRecorder()
+	require.NoError(cs.Bind(w, httptest.NewRequest(http.MethodGet, "/", nil)).Save(ctx, types.DefaultSessionKey, types.Session{
+		AccessToken:  "access-0",
+		RefreshToken: "refresh-0",
+		ExpiresAt:    time.Now().Add(time.Hour).Unix(),
+	}))
+	r := nextRequest(w, nil)
+	w = httptest.NewRecorder()
+	_, s, err := cs.Client(w, r, ts.client(), "", session.Config{})
+	require.NoError(err)
+	assert.Equal("access-0", s.AccessToken)
+	assert.Zero(ts.calls.Load())
+	assert.Empty(setCookies(w))
+
+	// A session about to expire is refreshed and written back.
+	w = httptest.NewRecorder()
+	require.NoError(cs.Bind(w, r).Save(ctx, types.DefaultSessionKey, types.Session{
+		AccessToken:  "access-0",
+		RefreshToken: "refresh-0",
+		ExpiresAt:    time.Now().Add(30 * time.Second).Unix(),
+	}))
+	r = nextRequest(w, r)
+	w = httptest.NewRecorder()
+	_, s, err = cs.Client(w, r, ts.client(), "", session.Config{})
+	require.NoError(err)
+	assert.Equal("access-1", s.AccessToken)
+	r = nextRequest(w, r)
+	stored, err := cs.Bind(httptest.NewRecorder(), r).Load(ctx, types.DefaultSessionKey)
+	require.NoError(err)
+	assert.Equal("refresh-1", stored.RefreshToken)
+
+	// A session that can no longer be refreshed is deleted.
+	ts.expired.Store(true)
+	w = httptest.NewRecorder()
+	_, _, err = cs.Client(w, r, ts.client(), "", session.Config{Skew: 2 * time.Hour})
+	require.Error(err)
+	assert.True(session.IsPermanent(err))
+	assert.Empty(nextRequest(w, r).Cookies())
+}
+
+func TestCookieStoreClientCancelled(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	ts := newTokenServer(t)
+	ts.delay = 100 * time.Millisecond
+	cs := session.NewCookieStore(session.CookieOptions{})
+
+	w := httptest.NewRecorder()
+	require.NoError(cs.Bind(w, httptest.NewRequest(http.MethodGet, "/", nil)).Save(context.Background(), types.DefaultSessionKey, types.Session{
+		AccessToken:  "access-0",
+		RefreshToken: "refresh-0",
+		ExpiresAt:    time.Now().Add(30 * time.Second).Unix(),
+	}))
+	r := nextRequest(w, nil)
+
+	// The request is cancelled while the session is being refreshed. The
+	// refreshed session must not be written once the handler has returned.
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	w = httptest.NewRecorder()
+	_, s, err := cs.Client(w, r.WithContext(ctx), ts.client(), "", session.Config{})
+	require.NoError(err)
+	assert.Equal("access-0", s.AccessToken)
+
+	time.Sleep(2 * ts.delay)
+	assert.Empty(setCookies(w))
+}
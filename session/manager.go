@@ -0,0 +1,306 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"sync"
+	"time"
+
+	auth "github.com/mrehanabbasi/supabase-auth-go"
+	"github.com/mrehanabbasi/supabase-auth-go/endpoints"
+	"github.com/mrehanabbasi/supabase-auth-go/types"
+)
+
+const (
+	defaultSkew          = 60 * time.Second
+	defaultRetryInterval = 5 * time.Second
+)
+
+var ErrNoRefreshToken = errors.New("session has no refresh token")
+
+type Config struct {
+	// Skew is how long before the access token expires that the session is
+	// refreshed. Defaults to 60 seconds.
+	Skew time.Duration
+	// Jitter is the upper bound of a random duration subtracted from each
+	// scheduled refresh, so that many processes started together do not all
+	// refresh at the same instant. Defaults to 0.
+	Jitter time.Duration
+	// RetryInterval is how long to wait before retrying a refresh that failed
+	// with a transient error. Defaults to 5 seconds.
+	RetryInterval time.Duration
+	// OnError, if set, is called every time a background refresh fails,
+	// whether or not the failure is permanent.
+	OnError func(error)
+}
+
+// Manager owns a types.Session and keeps it fresh by calling RefreshToken
+// shortly before the access token expires.
+//
+// Create a Manager using NewManager, then call Start to begin refreshing in
+// the background. Use Client to get a client carrying the current access
+// token.
+type Manager struct {
+	client auth.Client
+	cfg    Config
+
+	mu      sync.RWMutex
+	session types.Session
+
+	refreshMu sync.Mutex
+	inflight  *refreshCall
+
+	startOnce sync.Once
+	done      chan struct{}
+	err       error
+}
+
+type refreshCall struct {
+	done    chan struct{}
+	session types.Session
+	err     error
+}
+
+// Set up a new session manager.
+//
+// client: The client used to refresh the session. It should not carry a
+// token of its own, as the refresh_token grant does not need one.
+//
+// session: The session to manage, typically taken from the response of one of
+// the sign in methods.
+func NewManager(client auth.Client, session types.Session, cfg Config) *Manager {
+	if cfg.Skew <= 0 {
+		cfg.Skew = defaultSkew
+	}
+	if cfg.Jitter < 0 {
+		cfg.Jitter = 0
+	}
+	if cfg.RetryInterval <= 0 {
+		cfg.RetryInterval = defaultRetryInterval
+	}
+
+	return &Manager{
+		client:  client,
+		cfg:     cfg,
+		session: normalize(session),
+		done:    make(chan struct{}),
+	}
+}
+
+// Restore loads the session stored under key and returns a manager for it.
+// The manager's client persists every refreshed session back into store, so
+// the latest refresh token survives a restart. If key is empty,
+// types.DefaultSessionKey is used.
+//
+// If no session is stored, Restore returns types.ErrSessionNotStored.
+func Restore(ctx context.Context, client auth.Client, store types.SessionStore, key string, cfg Config) (*Manager, error) {
+	if key == "" {
+		key = types.DefaultSessionKey
+	}
+
+	session, err := store.Load(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewManager(client.WithSessionStore(store, key), *session, cfg), nil
+}
+
+// Session returns a copy of the current session.
+func (m *Manager) Session() types.Session {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.session
+}
+
+// Client returns a copy of the client carrying the current access token.
+//
+// The returned client is not updated when the session is refreshed, so call
+// Client again for each unit of work rather than holding on to the result.
+func (m *Manager) Client() auth.Client {
+	return m.client.WithToken(m.Session().AccessToken)
+}
+
+// Refresh refreshes the session immediately.
+//
+// Concurrent calls are collapsed into a single request to the Auth server,
+// and all callers receive the same result. Cancelling ctx only stops the
+// caller waiting; an in-flight refresh is allowed to complete so that the
+// rotated refresh token is not lost.
+func (m *Manager) Refresh(ctx context.Context) (*types.Session, error) {
+	m.refreshMu.Lock()
+	call := m.inflight
+	if call == nil {
+		call = &refreshCall{done: make(chan struct{})}
+		m.inflight = call
+		go m.refresh(context.WithoutCancel(ctx), call)
+	}
+	m.refreshMu.Unlock()
+
+	select {
+	case <-call.done:
+		if call.err != nil {
+			return nil, call.err
+		}
+		s := call.session
+		return &s, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (m *Manager) refresh(ctx context.Context, call *refreshCall) {
+	defer func() {
+		m.refreshMu.Lock()
+		m.inflight = nil
+		m.refreshMu.Unlock()
+		close(call.done)
+	}()
+
+	refreshToken := m.Session().RefreshToken
+	if refreshToken == "" {
+		call.err = ErrNoRefreshToken
+		return
+	}
+
+	// If the client has a session store that fails to persist the new
+	// session, both the response and an error are returned. Keep the new
+	// session in memory regardless, as the old refresh token is now spent.
+	res, err := m.client.RefreshToken(ctx, refreshToken)
+	if res != nil {
+		session := normalize(res.Session)
+		m.mu.Lock()
+		m.session = session
+		m.mu.Unlock()
+		call.session = session
+	}
+	call.err = err
+}
+
+// Start begins refreshing the session in a background goroutine. It returns
+// immediately. Calling Start more than once has no effect.
+//
+// The goroutine stops when ctx is cancelled, or when a refresh fails with a
+// permanent error such as endpoints.ErrRefreshTokenNotFound. In the latter
+// case, Err returns the error and the user must sign in again. If the session
+// has no ExpiresAt or ExpiresIn, no refresh is scheduled.
+func (m *Manager) Start(ctx context.Context) {
+	m.startOnce.Do(func() {
+		go m.run(ctx)
+	})
+}
+
+// Done returns a channel that is closed when the background refresh started
+// by Start has stopped.
+func (m *Manager) Done() <-chan struct{} {
+	return m.done
+}
+
+// Err returns the permanent error that stopped the background refresh, or nil
+// if it is still running or was stopped by cancelling its context.
+func (m *Manager) Err() error {
+	select {
+	case <-m.done:
+		return m.err
+	default:
+		return nil
+	}
+}
+
+func (m *Manager) run(ctx context.Context) {
+	defer close(m.done)
+
+	wait, scheduled := m.untilRefresh(false)
+	for {
+		if !scheduled {
+			// Without an expiry there is nothing to schedule.
+			<-ctx.Done()
+			return
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		_, err := m.Refresh(ctx)
+		if err == nil {
+			wait, scheduled = m.untilRefresh(true)
+			continue
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		if m.cfg.OnError != nil {
+			m.cfg.OnError(err)
+		}
+		if IsPermanent(err) {
+			m.err = err
+			return
+		}
+		wait = m.cfg.RetryInterval
+	}
+}
+
+// untilRefresh returns how long to wait before the next scheduled refresh,
+// or false if the session has no expiry to schedule it by.
+//
+// A session that is already due is refreshed straight away, unless it was
+// just refreshed. Tokens that live no longer than Skew, or that the local
+// clock already considers expired, would then be refreshed in a tight loop,
+// so they are refreshed halfway through their lifetime instead, and never
+// sooner than RetryInterval.
+func (m *Manager) untilRefresh(refreshed bool) (time.Duration, bool) {
+	expiresAt := m.Session().ExpiresAt
+	if expiresAt == 0 {
+		return 0, false
+	}
+
+	remaining := time.Until(time.Unix(expiresAt, 0))
+	wait := remaining - m.cfg.Skew
+	if m.cfg.Jitter > 0 {
+		wait -= rand.N(m.cfg.Jitter)
+	}
+	if refreshed {
+		wait = max(wait, remaining/2, m.cfg.RetryInterval)
+	}
+	return max(wait, 0), true
+}
+
+// IsPermanent reports whether a refresh error means the session can no
+// longer be refreshed, so the user needs to sign in again.
+//
+// Network failures, rate limiting and server errors are considered
+// transient.
+func IsPermanent(err error) bool {
+	if errors.Is(err, ErrNoRefreshToken) ||
+		errors.Is(err, endpoints.ErrRefreshTokenNotFound) ||
+		errors.Is(err, endpoints.ErrSessionNotFound) ||
+		errors.Is(err, endpoints.ErrInvalidJWT) {
+		return true
+	}
+
+	var apiErr *endpoints.APIError
+	if errors.As(err, &apiErr) {
+		code := apiErr.StatusCode
+		return code >= 400 && code < 500 &&
+			code != http.StatusRequestTimeout &&
+			code != http.StatusTooManyRequests
+	}
+
+	return false
+}
+
+// normalize fills in ExpiresAt for sessions that only carry ExpiresIn, such as
+// those built from the fragment of a redirect URL.
+func normalize(s types.Session) types.Session {
+	if s.ExpiresAt == 0 && s.ExpiresIn > 0 {
+		s.ExpiresAt = time.Now().Add(time.Duration(s.ExpiresIn) * time.Second).Unix()
+	}
+	return s
+}
@@ -0,0 +1,204 @@
+package session_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	auth "github.com/mrehanabbasi/supabase-auth-go"
+	"github.com/mrehanabbasi/supabase-auth-go/endpoints"
+	"github.com/mrehanabbasi/supabase-auth-go/session"
+	"github.com/mrehanabbasi/supabase-auth-go/types"
+)
+
+// tokenServer fakes the refresh_token grant of POST /token. Each refresh
+// returns a new access token and rotates the refresh token.
+type tokenServer struct {
+	*httptest.Server
+
+	calls   atomic.Int32
+	delay   time.Duration
+	expired atomic.Bool
+	// lifetime is how long the issued access tokens live. Defaults to an
+	// hour.
+	lifetime time.Duration
+}
+
+func newTokenServer(t *testing.T) *tokenServer {
+	ts := &tokenServer{}
+	ts.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/token" || r.URL.Query().Get("grant_type") != "refresh_token" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		n := ts.calls.Add(1)
+		time.Sleep(ts.delay)
+
+		w.Header().Set("Content-Type", "application/json")
+		if ts.expired.Load() {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"code":       400,
+				"error_code": "refresh_token_not_found",
+				"msg":        "Invalid Refresh Token: Refresh Token Not Found",
+			})
+			return
+		}
+		lifetime := ts.lifetime
+		if lifetime == 0 {
+			lifetime = time.Hour
+		}
+		_ = json.NewEncoder(w).Encode(types.Session{
+			AccessToken:  "access-" + string(rune('0'+n)),
+			RefreshToken: "refresh-" + string(rune('0'+n)),
+			TokenType:    "bearer",
+			ExpiresIn:    int(lifetime.Seconds()),
+			ExpiresAt:    time.Now().Add(lifetime).Unix(),
+		})
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func (ts *tokenServer) client() auth.Client {
+	return auth.NewWithCustomAuthURL(auth.Config{BaseURL: ts.URL, APIKey: "api_key"})
+}
+
+func TestManagerRefreshCollapsesConcurrentCalls(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	ts := newTokenServer(t)
+	ts.delay = 50 * time.Millisecond
+
+	m := session.NewManager(ts.client(), types.Session{
+		AccessToken:  "access-0",
+		RefreshToken: "refresh-0",
+		ExpiresAt:    time.Now().Add(time.Hour).Unix(),
+	}, session.Config{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s, err := m.Refresh(context.Background())
+			assert.NoError(err)
+			if s != nil {
+				assert.Equal("access-1", s.AccessToken)
+			}
+		}()
+	}
+	wg.Wait()
+
+	require.EqualValues(1, ts.calls.Load())
+	assert.Equal("refresh-1", m.Session().RefreshToken)
+}
+
+func TestManagerBackgroundRefresh(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	ts := newTokenServer(t)
+
+	// Expires within the skew, so the first refresh is immediate.
+	m := session.NewManager(ts.client(), types.Session{
+		AccessToken:  "access-0",
+		RefreshToken: "refresh-0",
+		ExpiresAt:    time.Now().Add(30 * time.Second).Unix(),
+	}, session.Config{Skew: time.Minute})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.Start(ctx)
+
+	require.Eventually(func() bool {
+		return m.Session().AccessToken == "access-1"
+	}, time.Second, 10*time.Millisecond)
+
+	cancel()
+	select {
+	case <-m.Done():
+	case <-time.After(time.Second):
+		t.Fatal("manager did not stop after context cancellation")
+	}
+	assert.NoError(m.Err())
+	assert.EqualValues(1, ts.calls.Load())
+}
+
+func TestManagerShortLivedTokens(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	ts := newTokenServer(t)
+	ts.lifetime = 30 * time.Second
+
+	// Tokens live for less than the skew, so each is due as soon as it is
+	// issued. After the first refresh, they are refreshed halfway through
+	// their lifetime instead of in a loop.
+	m := session.NewManager(ts.client(), types.Session{
+		AccessToken:  "access-0",
+		RefreshToken: "refresh-0",
+		ExpiresAt:    time.Now().Add(30 * time.Second).Unix(),
+	}, session.Config{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m.Start(ctx)
+
+	require.Eventually(func() bool {
+		return m.Session().AccessToken == "access-1"
+	}, time.Second, 10*time.Millisecond)
+	time.Sleep(200 * time.Millisecond)
+	assert.EqualValues(1, ts.calls.Load())
+}
+
+func TestManagerWithoutExpiry(t *testing.T) {
+	ts := newTokenServer(t)
+
+	m := session.NewManager(ts.client(), types.Session{
+		AccessToken:  "access-0",
+		RefreshToken: "refresh-0",
+	}, session.Config{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.Start(ctx)
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+	<-m.Done()
+
+	assert.EqualValues(t, 0, ts.calls.Load())
+}
+
+func TestManagerPermanentFailure(t *testing.T) {
+	assert := assert.New(t)
+
+	ts := newTokenServer(t)
+	ts.expired.Store(true)
+
+	var reported atomic.Int32
+	m := session.NewManager(ts.client(), types.Session{
+		AccessToken:  "access-0",
+		RefreshToken: "refresh-0",
+		ExpiresAt:    time.Now().Unix(),
+	}, session.Config{
+		OnError: func(error) { reported.Add(1) },
+	})
+	m.Start(context.Background())
+
+	select {
+	case <-m.Done():
+	case <-time.After(time.Second):
+		t.Fatal("manager did not stop after a permanent failure")
+	}
+	assert.ErrorIs(m.Err(), endpoints.ErrRefreshTokenNotFound)
+	assert.True(session.IsPermanent(m.Err()))
+	assert.EqualValues(1, reported.Load())
+}
@@ -0,0 +1,72 @@
+package session
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	auth "github.com/mrehanabbasi/supabase-auth-go"
+	"github.com/mrehanabbasi/supabase-auth-go/types"
+)
+
+// ClientFor loads the session stored under key and returns a client carrying
+// its access token, for services that restore the session on every request
+// rather than running a Manager. If the access token expires within
+// cfg.Skew, the session is refreshed first and the new session saved back
+// into store. If key is empty, types.DefaultSessionKey is used.
+//
+// The returned client also persists sessions into store, so calling Logout
+// with it deletes the stored session.
+//
+// If no session is stored, ClientFor returns types.ErrSessionNotStored. If
+// the refresh fails with a permanent error, the stored session is deleted
+// and the error returned, so the user needs to sign in again. A transient
+// failure is ignored as long as the access token has not yet expired.
+//
+// The refresh is made in the caller's goroutine and stops when ctx is done,
+// so store is never written to after ClientFor returns.
+func ClientFor(ctx context.Context, client auth.Client, store types.SessionStore, key string, cfg Config) (auth.Client, *types.Session, error) {
+	if key == "" {
+		key = types.DefaultSessionKey
+	}
+	if cfg.Skew <= 0 {
+		cfg.Skew = defaultSkew
+	}
+
+	stored, err := store.Load(ctx, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	session := normalize(*stored)
+	client = client.WithSessionStore(store, key)
+
+	if time.Until(time.Unix(session.ExpiresAt, 0)) <= cfg.Skew {
+		// Unlike Manager.Refresh, which lets the refresh finish in the
+		// background, so that a store bound to a ResponseWriter is not
+		// written to after the handler has returned.
+		err := ErrNoRefreshToken
+		if session.RefreshToken != "" {
+			var res *types.TokenResponse
+			res, err = client.RefreshToken(ctx, session.RefreshToken)
+			if res != nil {
+				session = normalize(res.Session)
+			}
+		}
+		if err != nil && IsPermanent(err) {
+			_ = store.Delete(ctx, key)
+			return nil, nil, err
+		}
+		if err != nil && time.Now().Unix() >= session.ExpiresAt {
+			return nil, nil, err
+		}
+	}
+
+	return client.WithToken(session.AccessToken), &session, nil
+}
+
+// Client calls ClientFor with the cookies of r, writing any refreshed session
+// to w. Call it before writing the response body, since cookies are sent as
+// headers.
+func (s *CookieStore) Client(w http.ResponseWriter, r *http.Request, client auth.Client, key string, cfg Config) (auth.Client, *types.Session, error) {
+	return ClientFor(r.Context(), client, s.Bind(w, r), key, cfg)
+}
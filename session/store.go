@@ -0,0 +1,221 @@
+package session
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/mrehanabbasi/supabase-auth-go/types"
+)
+
+var (
+	_ types.SessionStore = &MemoryStore{}
+	_ types.SessionStore = &FileStore{}
+
+	ErrInvalidEncryptionKey = errors.New("encryption key must be 16, 24 or 32 bytes")
+	ErrDecryptionFailed     = errors.New("failed to decrypt stored session")
+)
+
+// MemoryStore keeps sessions in memory. Sessions are lost when the process
+// exits, so it is mostly useful for tests and short-lived processes.
+type MemoryStore struct {
+	mu       sync.RWMutex
+	sessions map[string]types.Session
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		sessions: make(map[string]types.Session),
+	}
+}
+
+func (s *MemoryStore) Load(_ context.Context, key string) (*types.Session, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	session, ok := s.sessions[key]
+	if !ok {
+		return nil, types.ErrSessionNotStored
+	}
+	return &session, nil
+}
+
+func (s *MemoryStore) Save(_ context.Context, key string, session types.Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sessions[key] = session
+	return nil
+}
+
+func (s *MemoryStore) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.sessions, key)
+	return nil
+}
+
+// FileStore keeps each session in its own file inside a directory. Files are
+// created with 0600 permissions, and are replaced atomically so that a crash
+// while saving cannot leave a partially written session behind.
+//
+// Use NewFileStore for plain JSON files, or NewEncryptedFileStore to encrypt
+// the files with AES-GCM.
+type FileStore struct {
+	dir  string
+	aead cipher.AEAD
+
+	mu sync.Mutex
+}
+
+// Set up a new file store that saves sessions as plain JSON files in dir. The
+// directory is created with 0700 permissions if it does not exist.
+func NewFileStore(dir string) *FileStore {
+	return &FileStore{
+		dir: dir,
+	}
+}
+
+// Set up a new file store that saves sessions in dir, encrypted with AES-GCM.
+//
+// key: The AES key. It must be 16, 24 or 32 bytes long, selecting AES-128,
+// AES-192 or AES-256 respectively.
+func NewEncryptedFileStore(dir string, key []byte) (*FileStore, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, ErrInvalidEncryptionKey
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileStore{
+		dir:  dir,
+		aead: aead,
+	}, nil
+}
+
+func (s *FileStore) Load(_ context.Context, key string) (*types.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(s.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, types.ErrSessionNotStored
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if s.aead != nil {
+		data, err = s.decrypt(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var session types.Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, fmt.Errorf("failed to decode stored session: %w", err)
+	}
+	return &session, nil
+}
+
+func (s *FileStore) Save(_ context.Context, key string, session types.Session) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	if s.aead != nil {
+		data, err = s.encrypt(data)
+		if err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return err
+	}
+	return writeFileAtomic(s.path(key), data)
+}
+
+func (s *FileStore) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := os.Remove(s.path(key))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *FileStore) path(key string) string {
+	// Escaping keeps keys containing separators inside the directory.
+	return filepath.Join(s.dir, url.PathEscape(key)+".json")
+}
+
+// encrypt returns the nonce followed by the sealed data.
+func (s *FileStore) encrypt(data []byte) ([]byte, error) {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return s.aead.Seal(nonce, nonce, data, nil), nil
+}
+
+func (s *FileStore) decrypt(data []byte) ([]byte, error) {
+	n := s.aead.NonceSize()
+	if len(data) < n {
+		return nil, ErrDecryptionFailed
+	}
+	plain, err := s.aead.Open(nil, data[:n], data[n:], nil)
+	if err != nil {
+		return nil, ErrDecryptionFailed
+	}
+	return plain, nil
+}
+
+// writeFileAtomic writes data to a temporary file in the same directory as
+// path, syncs it to disk and renames it over path.
+func writeFileAtomic(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), ".session-*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	defer os.Remove(tmp) // No-op once renamed.
+
+	if err := f.Chmod(0o600); err != nil {
+		f.Close()
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
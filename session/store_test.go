@@ -0,0 +1,123 @@
+package session_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrehanabbasi/supabase-auth-go/session"
+	"github.com/mrehanabbasi/supabase-auth-go/types"
+)
+
+func TestStores(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, 32)
+	encrypted, err := session.NewEncryptedFileStore(t.TempDir(), key)
+	require.NoError(t, err)
+
+	stores := map[string]types.SessionStore{
+		"memory":    session.NewMemoryStore(),
+		"file":      session.NewFileStore(t.TempDir()),
+		"encrypted": encrypted,
+	}
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+			ctx := context.Background()
+
+			_, err := store.Load(ctx, "user/1")
+			require.ErrorIs(err, types.ErrSessionNotStored)
+
+			want := types.Session{
+				AccessToken:  "access",
+				RefreshToken: "refresh",
+				ExpiresAt:    123,
+			}
+			require.NoError(store.Save(ctx, "user/1", want))
+
+			got, err := store.Load(ctx, "user/1")
+			require.NoError(err)
+			assert.Equal(want, *got)
+
+			require.NoError(store.Delete(ctx, "user/1"))
+			require.NoError(store.Delete(ctx, "user/1"))
+			_, err = store.Load(ctx, "user/1")
+			assert.ErrorIs(err, types.ErrSessionNotStored)
+		})
+	}
+}
+
+func TestFileStorePermissions(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	dir := filepath.Join(t.TempDir(), "sessions")
+	store := session.NewFileStore(dir)
+	require.NoError(store.Save(context.Background(), "key", types.Session{RefreshToken: "refresh"}))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(err)
+	require.Len(entries, 1, "temporary files should not be left behind")
+
+	info, err := entries[0].Info()
+	require.NoError(err)
+	assert.Equal(os.FileMode(0o600), info.Mode().Perm())
+}
+
+func TestEncryptedFileStore(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	ctx := context.Background()
+
+	_, err := session.NewEncryptedFileStore(t.TempDir(), []byte("short"))
+	assert.ErrorIs(err, session.ErrInvalidEncryptionKey)
+
+	dir := t.TempDir()
+	store, err := session.NewEncryptedFileStore(dir, bytes.Repeat([]byte{1}, 32))
+	require.NoError(err)
+	require.NoError(store.Save(ctx, "key", types.Session{RefreshToken: "secret-refresh"}))
+
+	raw, err := os.ReadFile(filepath.Join(dir, "key.json"))
+	require.NoError(err)
+	assert.NotContains(string(raw), "secret-refresh")
+
+	other, err := session.NewEncryptedFileStore(dir, bytes.Repeat([]byte{2}, 32))
+	require.NoError(err)
+	_, err = other.Load(ctx, "key")
+	assert.ErrorIs(err, session.ErrDecryptionFailed)
+}
+
+func TestRestorePersistsRefreshedSession(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	ctx := context.Background()
+
+	ts := newTokenServer(t)
+	store := session.NewMemoryStore()
+
+	_, err := session.Restore(ctx, ts.client(), store, "", session.Config{})
+	require.ErrorIs(err, types.ErrSessionNotStored)
+
+	require.NoError(store.Save(ctx, types.DefaultSessionKey, types.Session{
+		AccessToken:  "access-0",
+		RefreshToken: "refresh-0",
+		ExpiresAt:    time.Now().Add(time.Hour).Unix(),
+	}))
+
+	m, err := session.Restore(ctx, ts.client(), store, "", session.Config{})
+	require.NoError(err)
+	assert.Equal("refresh-0", m.Session().RefreshToken)
+
+	_, err = m.Refresh(ctx)
+	require.NoError(err)
+
+	stored, err := store.Load(ctx, types.DefaultSessionKey)
+	require.NoError(err)
+	assert.Equal("refresh-1", stored.RefreshToken)
+}
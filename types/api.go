@@ -23,12 +23,28 @@ func (e *ErrInvalidGenerateLinkRequest) Error() string {
 	return fmt.Sprintf("generate link request is invalid - %s", e.message)
 }
 
+type ErrInvalidResendRequest struct {
+	message string
+}
+
+func NewErrInvalidResendRequest(message string) *ErrInvalidResendRequest {
+	return &ErrInvalidResendRequest{message: message}
+}
+
+func (e *ErrInvalidResendRequest) Error() string {
+	return fmt.Sprintf("resend request is invalid - %s", e.message)
+}
+
 var (
 	ErrInvalidAdminAuditRequest        = errors.New("admin audit request is invalid - if Query is not nil, then query Column must be author, action or type, and value must be given")
 	ErrInvalidAdminUpdateFactorRequest = errors.New("admin update factor request is invalid - nothing to update")
 	ErrInvalidTokenRequest             = errors.New("token request is invalid - grant_type must be either one of password, refresh_token, or pkce, email and password must be provided for grant_type=password, refresh_token must be provided for grant_type=refresh_token, auth_code and code_verifier must be provided for grant_type=pkce")
-	ErrInvalidVerifyRequest            = errors.New("verify request is invalid - type, token and redirect_to must be provided, and email or phone must be provided to VerifyForUser")
+	ErrInvalidVerifyRequest            = errors.New("verify request is invalid - type, token and redirect_to must be provided to Verify, and type and either token_hash, or token and email or phone, must be provided to VerifyForUser")
 	ErrInvalidProviderRequest          = errors.New("provider must be one of: github, apple, kakao, keycloak")
+	ErrInvalidConvertAnonymousRequest  = errors.New("convert anonymous user request is invalid - exactly one of email or phone must be provided")
+	ErrUserNotAnonymous                = errors.New("user is not anonymous")
+	ErrInvalidUnlinkIdentityRequest    = errors.New("unlink identity request is invalid - identity_id must be provided")
+	ErrInvalidLogoutRequest            = errors.New("logout request is invalid - scope must be one of global, local or others")
 )
 
 // --- Request/Response Types ---
@@ -301,6 +317,30 @@ type AuthorizeRequest struct {
 type AuthorizeResponse struct {
 	AuthorizationURL string
 	Verifier         string
+	// State is set if FlowType was pkce and the client has a VerifierStore.
+	State string
+}
+
+type LinkIdentityRequest struct {
+	Provider   Provider
+	RedirectTo string
+	FlowType   FlowType
+	Scopes     string
+}
+
+type LinkIdentityResponse struct {
+	AuthorizationURL string
+	Verifier         string
+	// State is set if FlowType was pkce and the client has a VerifierStore.
+	State string
+}
+
+type UnlinkIdentityRequest struct {
+	IdentityID uuid.UUID
+}
+
+type GetUserIdentitiesResponse struct {
+	Identities []Identity `json:"identities"`
 }
 
 // adapted from https://go-review.googlesource.com/c/oauth2/+/463979/9/pkce.go#64
@@ -310,6 +350,23 @@ type PKCEParams struct {
 	Verifier        string
 }
 
+type LogoutScope string
+
+const (
+	// LogoutScopeGlobal revokes every session of the user. This is the
+	// default.
+	LogoutScopeGlobal LogoutScope = "global"
+	// LogoutScopeLocal revokes only the session the access token belongs to.
+	LogoutScopeLocal LogoutScope = "local"
+	// LogoutScopeOthers revokes every session of the user except the one the
+	// access token belongs to.
+	LogoutScopeOthers LogoutScope = "others"
+)
+
+type LogoutRequest struct {
+	Scope LogoutScope
+}
+
 type FactorType string
 
 const FactorTypeTOTP FactorType = "totp"
@@ -379,27 +436,114 @@ type InviteResponse struct {
 type MagiclinkRequest struct {
 	Email string `json:"email"`
 
+	// RedirectTo is where the link sends the user. Defaults to the site URL.
+	RedirectTo string `json:"-"`
+
+	// Set FlowType to pkce to have the link carry an auth code instead of
+	// tokens. The code must be exchanged with ExchangeCodeForSession, using
+	// the verifier in the response.
+	FlowType FlowType `json:"-"`
+
 	// Provide Captcha token if enabled.
 	SecurityEmbed
 }
 
+type MagiclinkResponse struct {
+	// Verifier is set if FlowType was pkce.
+	Verifier string
+	// State is set if FlowType was pkce and the client has a VerifierStore.
+	State string
+}
+
 type OTPRequest struct {
 	Email      string                 `json:"email"`
 	Phone      string                 `json:"phone"`
 	CreateUser bool                   `json:"create_user"`
 	Data       map[string]interface{} `json:"data"`
 
+	// RedirectTo is where the link sends the user. Defaults to the site URL.
+	RedirectTo string `json:"-"`
+
+	// Set FlowType to pkce to have the link carry an auth code instead of
+	// tokens. The code must be exchanged with ExchangeCodeForSession, using
+	// the verifier in the response.
+	FlowType FlowType `json:"-"`
+
 	// Provide Captcha token if enabled.
 	SecurityEmbed
 }
 
+type OTPResponse struct {
+	// Verifier is set if FlowType was pkce.
+	Verifier string
+	// State is set if FlowType was pkce and the client has a VerifierStore.
+	State string
+}
+
 type RecoverRequest struct {
 	Email string `json:"email"`
 
+	// RedirectTo is where the link sends the user. Defaults to the site URL.
+	RedirectTo string `json:"-"`
+
+	// Set FlowType to pkce to have the link carry an auth code instead of
+	// tokens. The code must be exchanged with ExchangeCodeForSession, using
+	// the verifier in the response.
+	FlowType FlowType `json:"-"`
+
+	// Provide Captcha token if enabled.
+	SecurityEmbed
+}
+
+type RecoverResponse struct {
+	// Verifier is set if FlowType was pkce.
+	Verifier string
+	// State is set if FlowType was pkce and the client has a VerifierStore.
+	State string
+}
+
+type ResendType string
+
+const (
+	ResendTypeSignup      ResendType = "signup"
+	ResendTypeEmailChange ResendType = "email_change"
+	ResendTypeSMS         ResendType = "sms"
+	ResendTypePhoneChange ResendType = "phone_change"
+)
+
+type ResendRequest struct {
+	Type ResendType `json:"type"`
+	// Provide Email if Type is signup or email_change, and Phone if Type is
+	// sms or phone_change.
+	Email string `json:"email,omitempty"`
+	Phone string `json:"phone,omitempty"`
+
+	// EmailRedirectTo is where the user is redirected to after following the
+	// link in the email. Only used if Type is signup or email_change.
+	EmailRedirectTo string `json:"-"`
+
 	// Provide Captcha token if enabled.
 	SecurityEmbed
 }
 
+type ResendResponse struct {
+	// MessageID is the ID of the SMS sent by the SMS provider. Only set if
+	// Type is sms or phone_change.
+	MessageID string `json:"message_id,omitempty"`
+}
+
+type ExchangeCodeForSessionRequest struct {
+	// AuthCode is the code the user was redirected back with.
+	AuthCode string
+	// CodeVerifier is the verifier returned by the request that started the
+	// flow.
+	CodeVerifier string
+	// State can be given instead of CodeVerifier if the client has a
+	// VerifierStore. The verifier is loaded from the store and deleted, so
+	// that the code can only be exchanged once.
+	State string
+}
+
 type ExternalProviders struct {
 	Apple     bool `json:"apple"`
 	Azure     bool `json:"azure"`
@@ -439,6 +583,14 @@ type SignupRequest struct {
 	Password string                 `json:"password,omitempty"`
 	Data     map[string]interface{} `json:"data,omitempty"`
 
+	// RedirectTo is where the link sends the user. Defaults to the site URL.
+	RedirectTo string `json:"-"`
+
+	// Set FlowType to pkce to have the confirmation link carry an auth code
+	// instead of tokens. The code must be exchanged with
+	// ExchangeCodeForSession, using the verifier in the response.
+	FlowType FlowType `json:"-"`
+
 	// Provide Captcha token if enabled.
 	SecurityEmbed
 }
@@ -449,6 +601,33 @@ type SignupResponse struct {
 
 	// Response if autoconfirm is on
 	Session
+
+	// Verifier is set if FlowType was pkce.
+	Verifier string `json:"-"`
+	// State is set if FlowType was pkce and the client has a VerifierStore.
+	State string `json:"-"`
+}
+
+type SignInAnonymouslyRequest struct {
+	// Data is stored as the anonymous user's user_metadata.
+	Data map[string]interface{} `json:"data,omitempty"`
+
+	// Provide Captcha token if enabled.
+	SecurityEmbed
+}
+
+type SignInAnonymouslyResponse struct {
+	Session
+}
+
+type ConvertAnonymousUserRequest struct {
+	// Provide either Email or Phone.
+	Email string
+	Phone string
+	// Password is optional, and is set along with the email or phone.
+	Password string
+	// Data, if provided, is merged into the user's user_metadata.
+	Data map[string]interface{}
 }
 
 type SSORequest struct {
@@ -458,6 +637,11 @@ type SSORequest struct {
 	RedirectTo       string    `json:"redirect_to"`
 	SkipHTTPRedirect bool      `json:"skip_http_redirect"`
 
+	// Set FlowType to pkce to be redirected back with an auth code instead of
+	// tokens. The code must be exchanged with ExchangeCodeForSession, using
+	// the verifier in the response.
+	FlowType FlowType `json:"-"`
+
 	// Provide Captcha token if enabled.
 	SecurityEmbed
 }
@@ -468,6 +652,11 @@ type SSOResponse struct {
 
 	// Returned otherwise.
 	HTTPResponse *http.Response `json:"-"`
+
+	// Verifier is set if FlowType was pkce.
+	Verifier string `json:"-"`
+	// State is set if FlowType was pkce and the client has a VerifierStore.
+	State string `json:"-"`
 }
 
 type TokenRequest struct {
@@ -512,16 +701,30 @@ type UpdateUserRequest struct {
 	Data     map[string]interface{} `json:"data,omitempty"`
 	AppData  map[string]interface{} `json:"app_metadata,omitempty"`
 	Phone    string                 `json:"phone,omitempty"`
+
+	// RedirectTo is where the link sends the user. Defaults to the site URL.
+	RedirectTo string `json:"-"`
+
+	// Set FlowType to pkce to have the email change link carry an auth code
+	// instead of tokens. The code must be exchanged with
+	// ExchangeCodeForSession, using the verifier in the response.
+	FlowType FlowType `json:"-"`
 }
 
 type UpdateUserResponse struct {
 	User
+
+	// Verifier is set if FlowType was pkce.
+	Verifier string `json:"-"`
+	// State is set if FlowType was pkce and the client has a VerifierStore.
+	State string `json:"-"`
 }
 
 type VerificationType string
 
 const (
 	VerificationTypeSignup      = "signup"
+	VerificationTypeEmail       = "email"
 	VerificationTypeRecovery    = "recovery"
 	VerificationTypeInvite      = "invite"
 	VerificationTypeMagiclink   = "magiclink"
@@ -553,11 +756,19 @@ type VerifyResponse struct {
 }
 
 type VerifyForUserRequest struct {
-	Type       VerificationType `json:"type"`
-	Token      string           `json:"token"`
-	RedirectTo string           `json:"redirect_to"`
-	Email      string           `json:"email"`
-	Phone      string           `json:"phone"`
+	Type VerificationType `json:"type"`
+
+	// Provide either TokenHash, or Token and one of Email or Phone.
+	//
+	// TokenHash is the token_hash query parameter of the link sent in the
+	// email, when the email template links to your own site rather than the
+	// Auth server.
+	Token     string `json:"token,omitempty"`
+	TokenHash string `json:"token_hash,omitempty"`
+	Email     string `json:"email,omitempty"`
+	Phone     string `json:"phone,omitempty"`
+
+	RedirectTo string `json:"redirect_to,omitempty"`
 
 	// Provide Captcha token if enabled.
 	// Not required for server version >= v2.30.1
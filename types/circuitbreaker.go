@@ -0,0 +1,61 @@
+package types
+
+import "time"
+
+// CircuitState is the state of a client's circuit breaker.
+type CircuitState string
+
+const (
+	// CircuitClosed lets requests through while the Auth server is healthy.
+	CircuitClosed CircuitState = "closed"
+	// CircuitOpen fails requests without sending them, until OpenTimeout
+	// has passed.
+	CircuitOpen CircuitState = "open"
+	// CircuitHalfOpen lets a single probe through to find out whether the
+	// Auth server has recovered.
+	CircuitHalfOpen CircuitState = "half_open"
+)
+
+// CircuitBreakerPolicy controls when a client stops sending requests to an
+// Auth server that is failing, so that callers fail fast instead of waiting
+// for every request to time out. The zero value disables the breaker.
+//
+// Network errors and 5xx responses count as failures. Requests whose context
+// is cancelled, or that are refused before reaching the Auth server, e.g. by
+// an interceptor, count as neither failures nor successes. A call counts once,
+// however many times it is retried according to the client's RetryPolicy.
+type CircuitBreakerPolicy struct {
+	// ConsecutiveFailures opens the circuit after this many failures in a
+	// row. 0 disables this threshold.
+	ConsecutiveFailures int
+	// FailureRate opens the circuit once at least this fraction of the
+	// requests made within Window, e.g. 0.5, have failed. 0 disables this
+	// threshold.
+	FailureRate float64
+	// MinRequests is the fewest requests within Window for FailureRate to
+	// apply. Defaults to 10.
+	MinRequests int
+	// Window is how far back FailureRate looks. Defaults to 1 minute.
+	Window time.Duration
+	// OpenTimeout is how long the circuit stays open before a probe is let
+	// through. Defaults to 30 seconds.
+	OpenTimeout time.Duration
+	// ProbeWithHealthCheck probes the Auth server with HealthCheck when the
+	// circuit is half-open, instead of letting the next call through as the
+	// probe. If the server is healthy, the circuit closes and the call is
+	// sent.
+	ProbeWithHealthCheck bool
+	// OnStateChange, if set, is called whenever the circuit changes state,
+	// e.g. to log or export it.
+	OnStateChange func(from CircuitState, to CircuitState)
+}
+
+// DefaultCircuitBreakerPolicy opens the circuit after 5 failures in a row,
+// and probes the Auth server with HealthCheck on the first call made 30
+// seconds or more after it opened.
+func DefaultCircuitBreakerPolicy() CircuitBreakerPolicy {
+	return CircuitBreakerPolicy{
+		ConsecutiveFailures:  5,
+		ProbeWithHealthCheck: true,
+	}
+}
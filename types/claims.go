@@ -0,0 +1,35 @@
+package types
+
+import (
+	jwt "github.com/golang-jwt/jwt/v4"
+)
+
+type AAL string
+
+const (
+	AAL1 AAL = "aal1"
+	AAL2 AAL = "aal2"
+)
+
+type AMREntry struct {
+	Method    string `json:"method"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// Claims are the claims of an access token issued by the Auth server.
+//
+// The subject (user ID), audience and expiry are available through the
+// embedded jwt.RegisteredClaims.
+type Claims struct {
+	jwt.RegisteredClaims
+
+	Email        string                 `json:"email"`
+	Phone        string                 `json:"phone"`
+	Role         string                 `json:"role"`
+	SessionID    string                 `json:"session_id"`
+	AAL          AAL                    `json:"aal"`
+	AMR          []AMREntry             `json:"amr"`
+	IsAnonymous  bool                   `json:"is_anonymous"`
+	AppMetadata  map[string]interface{} `json:"app_metadata"`
+	UserMetadata map[string]interface{} `json:"user_metadata"`
+}
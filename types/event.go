@@ -0,0 +1,24 @@
+package types
+
+type AuthChangeEvent string
+
+const (
+	AuthChangeEventSignedIn             AuthChangeEvent = "SIGNED_IN"
+	AuthChangeEventSignedOut            AuthChangeEvent = "SIGNED_OUT"
+	AuthChangeEventTokenRefreshed       AuthChangeEvent = "TOKEN_REFRESHED"
+	AuthChangeEventUserUpdated          AuthChangeEvent = "USER_UPDATED"
+	AuthChangeEventPasswordRecovery     AuthChangeEvent = "PASSWORD_RECOVERY"
+	AuthChangeEventMFAChallengeVerified AuthChangeEvent = "MFA_CHALLENGE_VERIFIED"
+)
+
+type AuthStateChange struct {
+	Event AuthChangeEvent
+
+	// Session is set for every event except USER_UPDATED and SIGNED_OUT.
+	Session *Session
+	// User is set for every event except SIGNED_OUT.
+	User *User
+	// Dropped is the number of events before this one that were dropped,
+	// because the subscriber fell too far behind.
+	Dropped int
+}
@@ -7,7 +7,10 @@ import (
 )
 
 type Identity struct {
+	// ID is the user's ID at the provider. Use IdentityID to refer to the
+	// identity itself, e.g. in UnlinkIdentity.
 	ID           string                 `json:"id"`
+	IdentityID   uuid.UUID              `json:"identity_id"`
 	UserID       uuid.UUID              `json:"user_id"`
 	IdentityData map[string]interface{} `json:"identity_data,omitempty"`
 	Provider     string                 `json:"provider"`
@@ -0,0 +1,19 @@
+package types
+
+import "net/http"
+
+// RoundTrip sends a request to the Auth server and returns its response.
+type RoundTrip func(req *http.Request) (*http.Response, error)
+
+// Interceptor wraps every request made by a client.
+//
+// endpoint is the name of the client method making the request, e.g.
+// "GetUser" or "AdminCreateUser". The interceptor may modify req, e.g. to add
+// headers, before passing it to next, and inspect or replace the response
+// next returns. It may also short-circuit the request by returning a
+// response or error without calling next.
+//
+// Interceptors run once per call, outside any retries made according to the
+// client's RetryPolicy. The response body must be left readable for the
+// client to decode.
+type Interceptor func(endpoint string, req *http.Request, next RoundTrip) (*http.Response, error)
@@ -0,0 +1,24 @@
+package types
+
+// JWK is a public key from the Auth server's JSON Web Key Set, as described
+// in RFC 7517.
+type JWK struct {
+	Kty    string   `json:"kty"`
+	Kid    string   `json:"kid"`
+	Alg    string   `json:"alg,omitempty"`
+	Use    string   `json:"use,omitempty"`
+	KeyOps []string `json:"key_ops,omitempty"`
+
+	// RSA keys.
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+
+	// Elliptic curve keys.
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+type JWKSResponse struct {
+	Keys []JWK `json:"keys"`
+}
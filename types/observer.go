@@ -0,0 +1,50 @@
+package types
+
+import (
+	"context"
+	"time"
+)
+
+// Outcome classifies how a request to the Auth server ended.
+type Outcome string
+
+const (
+	// OutcomeSuccess is a 1xx, 2xx or 3xx response.
+	OutcomeSuccess Outcome = "success"
+	// OutcomeClientError is a 4xx response, e.g. invalid credentials.
+	OutcomeClientError Outcome = "client_error"
+	// OutcomeServerError is a 5xx response.
+	OutcomeServerError Outcome = "server_error"
+	// OutcomeNetworkError is a request that got no response.
+	OutcomeNetworkError Outcome = "network_error"
+	// OutcomeCanceled is a request whose context was cancelled or timed out.
+	OutcomeCanceled Outcome = "canceled"
+)
+
+// Observer receives traces and metrics for every request a client makes, so
+// that they can be exported to a tracing or metrics system. Each call is
+// labelled with its endpoint, the name of the client method making the
+// request, e.g. "Signup".
+//
+// For each request, StartSpan is called before it is sent, and the span is
+// ended once the response or error is received, after any retries. Then
+// IncCounter and ObserveLatency are called with the outcome.
+//
+// Implementations must be safe for concurrent use. The observer package
+// provides a Recorder for tests.
+type Observer interface {
+	// StartSpan starts a span for a request. The returned context is used
+	// for the request, so that the span can be propagated.
+	StartSpan(ctx context.Context, endpoint string) (context.Context, Span)
+	// IncCounter counts a finished request.
+	IncCounter(endpoint string, outcome Outcome)
+	// ObserveLatency records how long a finished request took.
+	ObserveLatency(endpoint string, outcome Outcome, duration time.Duration)
+}
+
+// Span is a span started by an Observer.
+type Span interface {
+	// End ends the span. status is the HTTP status of the response, or 0 if
+	// there was none, in which case err is the reason.
+	End(outcome Outcome, status int, err error)
+}
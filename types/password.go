@@ -0,0 +1,69 @@
+package types
+
+import (
+	"context"
+	"strings"
+)
+
+// DefaultPasswordMinLength is the Auth server's default minimum password
+// length.
+const DefaultPasswordMinLength = 6
+
+// Character sets for PasswordPolicy.RequiredCharacters, matching the options
+// offered by the Supabase dashboard.
+const (
+	PasswordCharactersLettersDigits           = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ:0123456789"
+	PasswordCharactersLowerUpperDigits        = "abcdefghijklmnopqrstuvwxyz:ABCDEFGHIJKLMNOPQRSTUVWXYZ:0123456789"
+	PasswordCharactersLowerUpperDigitsSymbols = PasswordCharactersLowerUpperDigits + ":" + `!@#$%^&*()_+-=[]{};'\:"|<>?,./` + "`~"
+)
+
+// PasswordPolicy mirrors the password rules the Auth server is configured
+// with, so that passwords can be checked before they are sent. The server
+// does not expose its rules through /settings, so copy them from your
+// project's configuration.
+type PasswordPolicy struct {
+	// MinLength is the shortest password allowed, in bytes, as set by
+	// GOTRUE_PASSWORD_MIN_LENGTH. Defaults to 6, the server's default.
+	MinLength int
+	// RequiredCharacters lists sets of characters, separated by ":", of
+	// which a password must contain at least one each, in the same format
+	// as GOTRUE_PASSWORD_REQUIRED_CHARACTERS. A ":" within a set is escaped
+	// as "\:". See the PasswordCharacters constants.
+	RequiredCharacters string
+	// Breached, if set, rejects passwords known to have been exposed in a
+	// data breach, as the server does when leaked password protection is
+	// enabled.
+	Breached BreachedPasswordChecker
+}
+
+// CharacterSets splits RequiredCharacters into its sets.
+func (p PasswordPolicy) CharacterSets() []string {
+	var sets []string
+	var b strings.Builder
+	for i := 0; i < len(p.RequiredCharacters); i++ {
+		ch := p.RequiredCharacters[i]
+		switch {
+		case ch == '\\' && i+1 < len(p.RequiredCharacters) && p.RequiredCharacters[i+1] == ':':
+			b.WriteByte(':')
+			i++
+		case ch == ':':
+			if b.Len() > 0 {
+				sets = append(sets, b.String())
+				b.Reset()
+			}
+		default:
+			b.WriteByte(ch)
+		}
+	}
+	if b.Len() > 0 {
+		sets = append(sets, b.String())
+	}
+	return sets
+}
+
+// BreachedPasswordChecker reports whether a password is known to have been
+// exposed in a data breach. See the breached package for an implementation
+// backed by a local list of hashes.
+type BreachedPasswordChecker interface {
+	IsBreached(ctx context.Context, password string) (bool, error)
+}
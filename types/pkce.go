@@ -0,0 +1,33 @@
+package types
+
+import (
+	"context"
+	"errors"
+)
+
+// StateParam is the query parameter that carries the state of a PKCE flow
+// back to the redirect URL, when the client is configured with a
+// VerifierStore.
+const StateParam = "state"
+
+var ErrVerifierNotFound = errors.New("no code verifier stored for the given state, or it has expired")
+
+// VerifierStore keeps PKCE code verifiers between starting a flow and
+// exchanging the auth code, keyed by a random state value.
+//
+// Implementations must be safe for concurrent use. The pkce package provides
+// an in-memory store and a cookie store that needs no server state.
+type VerifierStore interface {
+	// Save stores verifier under state.
+	Save(ctx context.Context, state string, verifier string) error
+	// Load returns the verifier stored under state. If there is none, or it
+	// has expired, it returns ErrVerifierNotFound.
+	Load(ctx context.Context, state string) (string, error)
+	// Take returns the verifier stored under state and removes it, as one
+	// step, so that of concurrent calls with the same state only one gets
+	// it. If there is none, or it has expired, it returns ErrVerifierNotFound.
+	Take(ctx context.Context, state string) (string, error)
+	// Delete removes the verifier stored under state. Deleting a state that
+	// has no verifier is not an error.
+	Delete(ctx context.Context, state string) error
+}
@@ -0,0 +1,49 @@
+package types
+
+import "time"
+
+// RateLimit is a token bucket. Up to Burst requests may be made at once, and
+// the bucket refills at Limit requests per Interval.
+type RateLimit struct {
+	Limit    int
+	Interval time.Duration
+	// Burst defaults to Limit.
+	Burst int
+}
+
+// RateLimitPolicy limits the requests a client makes, so that it stays
+// within the Auth server's rate limits instead of being rejected with a 429.
+// The zero value disables rate limiting.
+//
+// The limits are shared by every copy of the client made after the policy is
+// set, but not between separate clients or processes.
+type RateLimitPolicy struct {
+	// Endpoints limits each endpoint, keyed by the name of the client method,
+	// e.g. "Recover". Each endpoint has its own bucket, even though the Auth
+	// server's limit on emails sent is shared by every endpoint that sends
+	// them.
+	Endpoints map[string]RateLimit
+	// RecipientCooldown is the shortest time allowed between two messages
+	// sent to the same email address or phone number by Recover, Magiclink,
+	// OTP, Invite or Resend, whichever of them sends each. The Auth server
+	// allows one every 60 seconds by default.
+	RecipientCooldown time.Duration
+	// Wait makes calls wait until they are allowed, or until their context
+	// is done, instead of failing with an *endpoints.RateLimitError.
+	Wait bool
+}
+
+// DefaultRateLimitPolicy matches the Auth server's default limits on
+// refreshing sessions, verifying and sending OTPs, and its 60 second
+// cooldown between messages to the same recipient.
+func DefaultRateLimitPolicy() RateLimitPolicy {
+	return RateLimitPolicy{
+		Endpoints: map[string]RateLimit{
+			"Token":         {Limit: 150, Interval: 5 * time.Minute},
+			"Verify":        {Limit: 30, Interval: 5 * time.Minute},
+			"VerifyForUser": {Limit: 30, Interval: 5 * time.Minute},
+			"OTP":           {Limit: 30, Interval: 5 * time.Minute},
+		},
+		RecipientCooldown: 60 * time.Second,
+	}
+}
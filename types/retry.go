@@ -0,0 +1,45 @@
+package types
+
+import "time"
+
+// RetryPolicy controls how requests that fail with a transient error are
+// retried. The zero value disables retries.
+//
+// Idempotent requests (GET, HEAD and OPTIONS, calls made with a context from
+// endpoints.Idempotent, or any request carrying an Idempotency-Key header)
+// are retried on network errors and on 408, 429, 502, 503 and 504 responses.
+// Other requests, such as signing in or UpdateUser, are only retried if the
+// connection failed before any of the request was sent, unless
+// RetryNonIdempotent is set.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first. A
+	// value of 0 or 1 disables retries.
+	MaxAttempts int
+	// InitialInterval is the wait before the first retry. Defaults to 500
+	// milliseconds.
+	InitialInterval time.Duration
+	// MaxInterval caps the wait between attempts. Defaults to 10 seconds.
+	MaxInterval time.Duration
+	// Multiplier is the factor the wait grows by after each retry. Defaults
+	// to 2.
+	Multiplier float64
+	// RandomizationFactor spreads each wait randomly by up to this fraction
+	// in either direction, so that clients do not retry in lockstep. Defaults
+	// to 0.5. Set it to a negative value to disable jitter.
+	RandomizationFactor float64
+	// MaxRetryAfter is the longest Retry-After the client will wait for. If
+	// the Auth server asks for a longer wait, the response is returned
+	// without retrying. Defaults to MaxInterval.
+	MaxRetryAfter time.Duration
+	// RetryNonIdempotent allows requests that are not idempotent to be
+	// retried on the same responses as idempotent ones. Only enable it if
+	// repeating a request, e.g. sending an email twice, is acceptable.
+	RetryNonIdempotent bool
+}
+
+// DefaultRetryPolicy makes up to 3 attempts, with the default intervals.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+	}
+}
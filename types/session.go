@@ -1,5 +1,16 @@
 package types
 
+import (
+	"context"
+	"errors"
+)
+
+// DefaultSessionKey is the storage key used when a session store is
+// configured without an explicit key.
+const DefaultSessionKey = "supabase.auth.token"
+
+var ErrSessionNotStored = errors.New("no session stored under the given key")
+
 type Session struct {
 	AccessToken  string `json:"access_token"`
 	RefreshToken string `json:"refresh_token"`
@@ -8,3 +19,18 @@ type Session struct {
 	ExpiresAt    int64  `json:"expires_at"`
 	User         User   `json:"user"`
 }
+
+// SessionStore persists sessions so they survive process restarts.
+//
+// Implementations must be safe for concurrent use. The session package
+// provides in-memory, JSON file, encrypted file and cookie implementations.
+type SessionStore interface {
+	// Load returns the session stored under key. If there is none, it returns
+	// ErrSessionNotStored.
+	Load(ctx context.Context, key string) (*Session, error)
+	// Save stores the session under key, replacing any existing session.
+	Save(ctx context.Context, key string, session Session) error
+	// Delete removes the session stored under key. Deleting a key that has no
+	// session is not an error.
+	Delete(ctx context.Context, key string) error
+}
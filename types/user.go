@@ -41,6 +41,7 @@ type User struct {
 	CreatedAt   time.Time  `json:"created_at"`
 	UpdatedAt   time.Time  `json:"updated_at"`
 	BannedUntil *time.Time `json:"banned_until,omitempty"`
+	IsAnonymous bool       `json:"is_anonymous"`
 
 	// ConfirmedAt is deprecated. Use EmailConfirmedAt or PhoneConfirmedAt instead.
 	ConfirmedAt time.Time `json:"confirmed_at"`
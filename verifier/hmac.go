@@ -0,0 +1,35 @@
+package verifier
+
+import (
+	"context"
+
+	jwt "github.com/golang-jwt/jwt/v4"
+
+	"github.com/mrehanabbasi/supabase-auth-go/types"
+)
+
+var _ Verifier = &HS256Verifier{}
+
+// HS256Verifier verifies access tokens signed with the project's shared JWT
+// secret.
+type HS256Verifier struct {
+	secret []byte
+	opts   Options
+}
+
+// Set up a new verifier for tokens signed with HS256.
+//
+// secret: The JWT secret of your Supabase project. It can be found in the
+// Supabase dashboard under project settings. KEEP IT SECRET!!!
+func NewHS256(secret []byte, opts Options) *HS256Verifier {
+	return &HS256Verifier{
+		secret: secret,
+		opts:   opts.withDefaults(),
+	}
+}
+
+func (v *HS256Verifier) Verify(_ context.Context, token string) (*types.Claims, error) {
+	return parse(token, []string{jwt.SigningMethodHS256.Alg()}, func(*jwt.Token) (interface{}, error) {
+		return v.secret, nil
+	}, v.opts)
+}
@@ -0,0 +1,122 @@
+package verifier_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrehanabbasi/supabase-auth-go/types"
+	"github.com/mrehanabbasi/supabase-auth-go/verifier"
+)
+
+const jwtSecret = "secret"
+
+func userClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub":        "8d7b1b4c-1f0e-4a52-8d3c-3a1e5c2f6b90",
+		"aud":        "authenticated",
+		"exp":        time.Now().Add(time.Hour).Unix(),
+		"role":       "authenticated",
+		"email":      "user@test.com",
+		"session_id": "f3f0a1e2-5c6d-4b7a-9e8f-0a1b2c3d4e5f",
+		"aal":        "aal2",
+		"amr": []map[string]interface{}{
+			{"method": "password", "timestamp": 1700000000},
+			{"method": "totp", "timestamp": 1700000100},
+		},
+		"is_anonymous":  false,
+		"app_metadata":  map[string]interface{}{"provider": "email", "org_id": "acme"},
+		"user_metadata": map[string]interface{}{"name": "Test"},
+	}
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, key interface{}, claims jwt.MapClaims) string {
+	token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+	require.NoError(t, err)
+	return token
+}
+
+func TestHS256Verifier(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	ctx := context.Background()
+
+	v := verifier.NewHS256([]byte(jwtSecret), verifier.Options{})
+
+	claims, err := v.Verify(ctx, sign(t, jwt.SigningMethodHS256, []byte(jwtSecret), userClaims()))
+	require.NoError(err)
+	assert.Equal("8d7b1b4c-1f0e-4a52-8d3c-3a1e5c2f6b90", claims.Subject)
+	assert.Equal(jwt.ClaimStrings{"authenticated"}, claims.Audience)
+	assert.Equal("authenticated", claims.Role)
+	assert.Equal("user@test.com", claims.Email)
+	assert.Equal("f3f0a1e2-5c6d-4b7a-9e8f-0a1b2c3d4e5f", claims.SessionID)
+	assert.Equal(types.AAL2, claims.AAL)
+	assert.Equal([]types.AMREntry{
+		{Method: "password", Timestamp: 1700000000},
+		{Method: "totp", Timestamp: 1700000100},
+	}, claims.AMR)
+	assert.False(claims.IsAnonymous)
+	assert.Equal("acme", claims.AppMetadata["org_id"])
+	assert.Equal("Test", claims.UserMetadata["name"])
+
+	expired := userClaims()
+	expired["exp"] = time.Now().Add(-time.Minute).Unix()
+	wrongAudience := userClaims()
+	wrongAudience["aud"] = "admin"
+	missingExpiry := userClaims()
+	delete(missingExpiry, "exp")
+
+	tests := map[string]struct {
+		token string
+		err   error
+	}{
+		"expired": {
+			token: sign(t, jwt.SigningMethodHS256, []byte(jwtSecret), expired),
+			err:   verifier.ErrTokenExpired,
+		},
+		"wrong_audience": {
+			token: sign(t, jwt.SigningMethodHS256, []byte(jwtSecret), wrongAudience),
+			err:   verifier.ErrInvalidAudience,
+		},
+		"bad_signature": {
+			token: sign(t, jwt.SigningMethodHS256, []byte("not the secret"), userClaims()),
+			err:   verifier.ErrInvalidSignature,
+		},
+		"wrong_algorithm": {
+			token: sign(t, jwt.SigningMethodHS512, []byte(jwtSecret), userClaims()),
+			err:   verifier.ErrUnsupportedAlgorithm,
+		},
+		"unsigned": {
+			token: sign(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, userClaims()),
+			err:   verifier.ErrUnsupportedAlgorithm,
+		},
+		"malformed": {
+			token: "not.a.jwt",
+			err:   verifier.ErrMalformedToken,
+		},
+		"missing_expiry": {
+			token: sign(t, jwt.SigningMethodHS256, []byte(jwtSecret), missingExpiry),
+			err:   verifier.ErrMalformedToken,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := v.Verify(ctx, test.token)
+			assert.ErrorIs(err, test.err)
+		})
+	}
+
+	// Leeway and audience options.
+	lenient := verifier.NewHS256([]byte(jwtSecret), verifier.Options{
+		SkipAudienceCheck: true,
+		Leeway:            2 * time.Minute,
+	})
+	_, err = lenient.Verify(ctx, tests["expired"].token)
+	assert.NoError(err)
+	_, err = lenient.Verify(ctx, tests["wrong_audience"].token)
+	assert.NoError(err)
+}
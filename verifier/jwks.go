@@ -0,0 +1,237 @@
+package verifier
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v4"
+
+	"github.com/mrehanabbasi/supabase-auth-go/types"
+)
+
+const (
+	defaultJWKSCacheTTL           = 10 * time.Minute
+	defaultJWKSMinRefreshInterval = 30 * time.Second
+)
+
+var (
+	_ Verifier = &JWKSVerifier{}
+
+	ErrUnknownKeyID = errors.New("token is signed with an unknown key")
+)
+
+// JWKSFetcher fetches the JSON Web Key Set of the Auth server. It is
+// implemented by auth.Client.
+type JWKSFetcher interface {
+	GetJWKS(ctx context.Context) (*types.JWKSResponse, error)
+}
+
+type JWKSOptions struct {
+	Options
+
+	// CacheTTL is how long a fetched key set is used before it is fetched
+	// again. Defaults to 10 minutes.
+	CacheTTL time.Duration
+	// MinRefreshInterval is the minimum time between fetches triggered by a
+	// token signed with a key that is not in the cached set. This stops
+	// tokens with made up key IDs from flooding the Auth server. Defaults to
+	// 30 seconds.
+	MinRefreshInterval time.Duration
+}
+
+// JWKSVerifier verifies access tokens signed with the asymmetric keys
+// published by the Auth server at /.well-known/jwks.json. Only public keys
+// are needed, so services using it never hold the project's JWT secret.
+//
+// RS256 and ES256 signatures are supported.
+type JWKSVerifier struct {
+	fetcher JWKSFetcher
+	opts    JWKSOptions
+
+	mu          sync.Mutex
+	keys        map[string]interface{}
+	fetchedAt   time.Time
+	attemptedAt time.Time
+	fetchErr    error
+	inflight    *jwksFetch
+}
+
+type jwksFetch struct {
+	done chan struct{}
+}
+
+// Set up a new verifier backed by the Auth server's JSON Web Key Set.
+//
+// fetcher: Usually your auth.Client. Keys are fetched using its base URL and
+// HTTP client.
+func NewJWKS(fetcher JWKSFetcher, opts JWKSOptions) *JWKSVerifier {
+	opts.Options = opts.Options.withDefaults()
+	if opts.CacheTTL <= 0 {
+		opts.CacheTTL = defaultJWKSCacheTTL
+	}
+	if opts.MinRefreshInterval <= 0 {
+		opts.MinRefreshInterval = defaultJWKSMinRefreshInterval
+	}
+
+	return &JWKSVerifier{
+		fetcher: fetcher,
+		opts:    opts,
+	}
+}
+
+func (v *JWKSVerifier) Verify(ctx context.Context, token string) (*types.Claims, error) {
+	methods := []string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodES256.Alg()}
+	return parse(token, methods, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		key, err := v.key(ctx, kid)
+		if err != nil {
+			return nil, err
+		}
+
+		switch key.(type) {
+		case *rsa.PublicKey:
+			if t.Method.Alg() != jwt.SigningMethodRS256.Alg() {
+				return nil, newVerificationError(ErrUnsupportedAlgorithm, errors.New("key type does not match signing method"))
+			}
+		case *ecdsa.PublicKey:
+			if t.Method.Alg() != jwt.SigningMethodES256.Alg() {
+				return nil, newVerificationError(ErrUnsupportedAlgorithm, errors.New("key type does not match signing method"))
+			}
+		}
+		return key, nil
+	}, v.opts.Options)
+}
+
+// key returns the public key with the given ID. The key set is fetched when
+// the cache has expired or the key is not in it, but at most once per
+// MinRefreshInterval.
+//
+// The lock is not held during the fetch, so keys that are cached and fresh
+// are returned without waiting. Concurrent calls needing a fetch share a
+// single request to the Auth server.
+func (v *JWKSVerifier) key(ctx context.Context, kid string) (interface{}, error) {
+	v.mu.Lock()
+	now := time.Now()
+	key, ok := v.keys[kid]
+	stale := now.Sub(v.fetchedAt) >= v.opts.CacheTTL
+	call := v.inflight
+	if call == nil && (stale || !ok) && now.Sub(v.attemptedAt) >= v.opts.MinRefreshInterval {
+		v.attemptedAt = now
+		call = &jwksFetch{done: make(chan struct{})}
+		v.inflight = call
+		// Cancelling ctx only stops the caller waiting, so that the other
+		// callers still get the fetched keys.
+		go v.fetch(context.WithoutCancel(ctx), call)
+	}
+	v.mu.Unlock()
+
+	if ok && (!stale || call == nil) {
+		return key, nil
+	}
+
+	if call != nil {
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	// If the Auth server is unreachable, keep serving the cached keys.
+	if key, ok := v.keys[kid]; ok {
+		return key, nil
+	}
+	if v.fetchErr != nil {
+		return nil, v.fetchErr
+	}
+	return nil, ErrUnknownKeyID
+}
+
+func (v *JWKSVerifier) fetch(ctx context.Context, call *jwksFetch) {
+	keys, err := v.fetchKeys(ctx)
+
+	v.mu.Lock()
+	if err == nil {
+		v.keys = keys
+		v.fetchedAt = time.Now()
+	}
+	v.fetchErr = err
+	v.inflight = nil
+	v.mu.Unlock()
+	close(call.done)
+}
+
+func (v *JWKSVerifier) fetchKeys(ctx context.Context) (map[string]interface{}, error) {
+	res, err := v.fetcher.GetJWKS(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+
+	keys := make(map[string]interface{}, len(res.Keys))
+	for _, jwk := range res.Keys {
+		key, err := parseJWK(jwk)
+		if err != nil {
+			// Skip keys this verifier cannot use, such as symmetric keys or
+			// unsupported curves.
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	return keys, nil
+}
+
+func parseJWK(jwk types.JWK) (interface{}, error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decodeBigInt(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() {
+			return nil, errors.New("RSA exponent is too large")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		if jwk.Crv != "P-256" {
+			return nil, fmt.Errorf("unsupported curve %q", jwk.Crv)
+		}
+		x, err := decodeBigInt(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		key := &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+		// Conversion fails if the point is not on the curve.
+		if _, err := key.ECDH(); err != nil {
+			return nil, err
+		}
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", jwk.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,222 @@
+package verifier_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	auth "github.com/mrehanabbasi/supabase-auth-go"
+	"github.com/mrehanabbasi/supabase-auth-go/types"
+	"github.com/mrehanabbasi/supabase-auth-go/verifier"
+)
+
+// jwksServer serves a key set that can be replaced to simulate key rotation.
+type jwksServer struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	keys    []types.JWK
+	fetches atomic.Int32
+}
+
+func newJWKSServer(t *testing.T, keys ...types.JWK) *jwksServer {
+	js := &jwksServer{keys: keys}
+	js.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/.well-known/jwks.json" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		js.fetches.Add(1)
+		js.mu.Lock()
+		defer js.mu.Unlock()
+		_ = json.NewEncoder(w).Encode(types.JWKSResponse{Keys: js.keys})
+	}))
+	t.Cleanup(js.Close)
+	return js
+}
+
+func (js *jwksServer) setKeys(keys ...types.JWK) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	js.keys = keys
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func rsaJWK(kid string, key *rsa.PrivateKey) types.JWK {
+	return types.JWK{
+		Kty: "RSA",
+		Kid: kid,
+		Alg: "RS256",
+		N:   b64(key.N.Bytes()),
+		E:   b64(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func ecJWK(kid string, key *ecdsa.PrivateKey) types.JWK {
+	return types.JWK{
+		Kty: "EC",
+		Kid: kid,
+		Alg: "ES256",
+		Crv: "P-256",
+		X:   b64(key.X.FillBytes(make([]byte, 32))),
+		Y:   b64(key.Y.FillBytes(make([]byte, 32))),
+	}
+}
+
+func signWithKid(t *testing.T, method jwt.SigningMethod, kid string, key interface{}) string {
+	token := jwt.NewWithClaims(method, userClaims())
+	token.Header["kid"] = kid
+	s, err := token.SignedString(key)
+	require.NoError(t, err)
+	return s
+}
+
+func TestJWKSVerifier(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	ctx := context.Background()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(err)
+	rotatedKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(err)
+
+	js := newJWKSServer(t, rsaJWK("rsa-1", rsaKey), ecJWK("ec-1", ecKey))
+	client := auth.NewWithCustomAuthURL(auth.Config{BaseURL: js.URL, APIKey: "api_key"})
+	v := verifier.NewJWKS(client, verifier.JWKSOptions{
+		MinRefreshInterval: 100 * time.Millisecond,
+	})
+
+	// Both algorithms verify, and the key set is fetched once.
+	claims, err := v.Verify(ctx, signWithKid(t, jwt.SigningMethodRS256, "rsa-1", rsaKey))
+	require.NoError(err)
+	assert.Equal("user@test.com", claims.Email)
+	claims, err = v.Verify(ctx, signWithKid(t, jwt.SigningMethodES256, "ec-1", ecKey))
+	require.NoError(err)
+	assert.Equal(types.AAL2, claims.AAL)
+	assert.EqualValues(1, js.fetches.Load())
+
+	// Wrong key for the kid.
+	_, err = v.Verify(ctx, signWithKid(t, jwt.SigningMethodES256, "ec-1", rotatedKey))
+	assert.ErrorIs(err, verifier.ErrInvalidSignature)
+
+	// The shared HS256 secret is not accepted.
+	_, err = v.Verify(ctx, signWithKid(t, jwt.SigningMethodHS256, "rsa-1", []byte(jwtSecret)))
+	assert.ErrorIs(err, verifier.ErrUnsupportedAlgorithm)
+
+	// Nor is a key of the wrong type for the kid.
+	_, err = v.Verify(ctx, signWithKid(t, jwt.SigningMethodES256, "rsa-1", ecKey))
+	assert.ErrorIs(err, verifier.ErrUnsupportedAlgorithm)
+
+	// Keys are rotated. An unknown kid triggers a refetch, but only once per
+	// MinRefreshInterval.
+	js.setKeys(ecJWK("ec-2", rotatedKey))
+	time.Sleep(150 * time.Millisecond)
+
+	rotated := signWithKid(t, jwt.SigningMethodES256, "ec-2", rotatedKey)
+	_, err = v.Verify(ctx, rotated)
+	require.NoError(err)
+	assert.EqualValues(2, js.fetches.Load())
+
+	_, err = v.Verify(ctx, signWithKid(t, jwt.SigningMethodES256, "made-up", rotatedKey))
+	assert.ErrorIs(err, verifier.ErrUnknownKeyID)
+	_, err = v.Verify(ctx, signWithKid(t, jwt.SigningMethodES256, "made-up", rotatedKey))
+	assert.ErrorIs(err, verifier.ErrUnknownKeyID)
+	assert.EqualValues(2, js.fetches.Load())
+
+	// Keys removed from the set are no longer accepted.
+	_, err = v.Verify(ctx, signWithKid(t, jwt.SigningMethodRS256, "rsa-1", rsaKey))
+	assert.ErrorIs(err, verifier.ErrUnknownKeyID)
+}
+
+// blockingFetcher serves keys, holding every fetch after the first until
+// release is closed.
+type blockingFetcher struct {
+	mu      sync.Mutex
+	keys    []types.JWK
+	release chan struct{}
+	fetches atomic.Int32
+}
+
+func (f *blockingFetcher) GetJWKS(context.Context) (*types.JWKSResponse, error) {
+	if f.fetches.Add(1) > 1 {
+		<-f.release
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return &types.JWKSResponse{Keys: f.keys}, nil
+}
+
+func TestJWKSVerifierConcurrentFetch(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	ctx := context.Background()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(err)
+
+	fetcher := &blockingFetcher{keys: []types.JWK{rsaJWK("rsa-1", rsaKey)}, release: make(chan struct{})}
+	v := verifier.NewJWKS(fetcher, verifier.JWKSOptions{MinRefreshInterval: time.Nanosecond})
+
+	cached := signWithKid(t, jwt.SigningMethodRS256, "rsa-1", rsaKey)
+	_, err = v.Verify(ctx, cached)
+	require.NoError(err)
+
+	fetcher.mu.Lock()
+	fetcher.keys = append(fetcher.keys, ecJWK("ec-1", ecKey))
+	fetcher.mu.Unlock()
+
+	// Tokens signed with a new key wait for a single, slow fetch.
+	rotated := signWithKid(t, jwt.SigningMethodES256, "ec-1", ecKey)
+	var wg sync.WaitGroup
+	errs := make(chan error, 5)
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := v.Verify(ctx, rotated)
+			errs <- err
+		}()
+	}
+	require.Eventually(func() bool { return fetcher.fetches.Load() == 2 }, time.Second, time.Millisecond)
+
+	// Meanwhile, cached keys are verified without waiting.
+	_, err = v.Verify(ctx, cached)
+	require.NoError(err)
+
+	// A caller that gives up does not cancel the fetch for the others.
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = v.Verify(cancelled, signWithKid(t, jwt.SigningMethodES256, "ec-2", ecKey))
+	assert.ErrorIs(err, context.Canceled)
+
+	close(fetcher.release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		assert.NoError(err)
+	}
+	assert.EqualValues(2, fetcher.fetches.Load())
+}
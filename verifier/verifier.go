@@ -0,0 +1,114 @@
+package verifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v4"
+
+	"github.com/mrehanabbasi/supabase-auth-go/types"
+)
+
+// DefaultAudience is the audience of access tokens issued to signed in users.
+const DefaultAudience = "authenticated"
+
+var (
+	ErrTokenExpired     = errors.New("token is expired")
+	ErrTokenNotValidYet = errors.New("token is not valid yet")
+	ErrInvalidAudience  = errors.New("token has an invalid audience")
+	ErrInvalidSignature = errors.New("token signature is invalid")
+	ErrMalformedToken   = errors.New("token is malformed")
+	// ErrUnsupportedAlgorithm is returned for tokens signed with an algorithm
+	// the verifier does not accept, or with a key of the wrong type for it.
+	// The signature is not checked, so it says nothing about whether the
+	// Auth server issued the token.
+	ErrUnsupportedAlgorithm = errors.New("token is signed with an unsupported algorithm")
+)
+
+// Verifier validates access tokens issued by the Auth server without making
+// a request to it.
+type Verifier interface {
+	// Verify checks the signature, expiry and audience of token and returns
+	// its claims.
+	Verify(ctx context.Context, token string) (*types.Claims, error)
+}
+
+type Options struct {
+	// Audience is the expected aud claim. Defaults to DefaultAudience.
+	Audience string
+	// SkipAudienceCheck disables checking the aud claim. Use this to accept
+	// tokens issued for any audience.
+	SkipAudienceCheck bool
+	// Leeway is the clock skew tolerated when checking exp and nbf.
+	Leeway time.Duration
+}
+
+func (o Options) withDefaults() Options {
+	if o.Audience == "" {
+		o.Audience = DefaultAudience
+	}
+	return o
+}
+
+// parse verifies the signature of token using the key returned by keyFunc,
+// then validates its claims. Errors returned by keyFunc are returned as is.
+func parse(token string, methods []string, keyFunc jwt.Keyfunc, opts Options) (*types.Claims, error) {
+	// Claims are validated below rather than by the parser, so that the
+	// leeway and audience options can be applied. The signing method is
+	// checked in the key func, so that it can be told apart from a bad
+	// signature.
+	parser := jwt.NewParser(jwt.WithoutClaimsValidation())
+
+	var keyErr error
+	var claims types.Claims
+	_, err := parser.ParseWithClaims(token, &claims, func(t *jwt.Token) (interface{}, error) {
+		if !slices.Contains(methods, t.Method.Alg()) {
+			keyErr = newVerificationError(ErrUnsupportedAlgorithm, fmt.Errorf("signing method %s is not accepted", t.Method.Alg()))
+			return nil, keyErr
+		}
+		key, err := keyFunc(t)
+		keyErr = err
+		return key, err
+	})
+	if keyErr != nil {
+		return nil, keyErr
+	}
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenMalformed) {
+			return nil, newVerificationError(ErrMalformedToken, err)
+		}
+		return nil, newVerificationError(ErrInvalidSignature, err)
+	}
+
+	if err := validateClaims(&claims, opts); err != nil {
+		return nil, err
+	}
+
+	return &claims, nil
+}
+
+func validateClaims(claims *types.Claims, opts Options) error {
+	now := time.Now()
+
+	if claims.ExpiresAt == nil {
+		return newVerificationError(ErrMalformedToken, errors.New("missing exp claim"))
+	}
+	if now.After(claims.ExpiresAt.Add(opts.Leeway)) {
+		return ErrTokenExpired
+	}
+	if claims.NotBefore != nil && now.Add(opts.Leeway).Before(claims.NotBefore.Time) {
+		return ErrTokenNotValidYet
+	}
+	if !opts.SkipAudienceCheck && !claims.VerifyAudience(opts.Audience, true) {
+		return ErrInvalidAudience
+	}
+
+	return nil
+}
+
+func newVerificationError(sentinel error, err error) error {
+	return fmt.Errorf("%w: %s", sentinel, err)
+}